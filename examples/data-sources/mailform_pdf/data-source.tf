@@ -0,0 +1,29 @@
+terraform {
+  required_providers {
+    mailform = {
+      source = "circa10a/mailform"
+    }
+  }
+}
+
+data "mailform_pdf" "letter" {
+  header  = "Hello"
+  content = "Rendered in memory, nothing is written to disk"
+}
+
+resource "mailform_order" "letter" {
+  pdf_content_base64 = data.mailform_pdf.letter.content_base64
+  service            = "USPS_PRIORITY"
+  to_name            = "A Name"
+  to_address_1       = "Address 1"
+  to_city            = "Seattle"
+  to_state           = "WA"
+  to_postcode        = "00000"
+  to_country         = "US"
+  from_name          = "From Name"
+  from_address_1     = "Address 1"
+  from_city          = "Seattle"
+  from_state         = "WA"
+  from_postcode      = "00000"
+  from_country       = "US"
+}
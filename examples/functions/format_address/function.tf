@@ -0,0 +1,14 @@
+locals {
+  recipient = {
+    name      = "Jane Doe"
+    address_1 = "1 Main St"
+    city      = "Seattle"
+    state     = "WA"
+    postcode  = "98101"
+    country   = "US"
+  }
+}
+
+output "recipient" {
+  value = provider::mailform::format_address(local.recipient)
+}
@@ -0,0 +1,8 @@
+variable "to_postcode" {
+  type = string
+
+  validation {
+    condition     = provider::mailform::validate_postcode("US", var.to_postcode)
+    error_message = "The recipient postcode is not a valid US zip code."
+  }
+}
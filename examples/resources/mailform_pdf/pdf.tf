@@ -16,3 +16,160 @@ resource "mailform_pdf" "converted_image" {
   image_filename = "./myimage.jpg"
   filename       = "./myimage.pdf"
 }
+
+resource "mailform_pdf" "unicode" {
+  header    = "Zahlungserinnerung"
+  content   = "Sehr geehrte Frau Müller, bitte überweisen Sie 120 €."
+  filename  = "./unicode.pdf"
+  font_file = "./DejaVuSans.ttf"
+  fonts {
+    bold = "./DejaVuSans-Bold.ttf"
+  }
+}
+
+resource "mailform_pdf" "a4_letter" {
+  header      = "Notice"
+  content     = "Some notice contents"
+  filename    = "./notice.pdf"
+  page_size   = "A4"
+  font_size   = 12
+  line_height = 6
+  margins {
+    top    = 25
+    bottom = 25
+  }
+}
+
+resource "mailform_pdf" "postcard" {
+  image_filename = "./myimage.jpg"
+  filename       = "./postcard.pdf"
+  page_size      = "Postcard4x6"
+  orientation    = "landscape"
+}
+
+resource "mailform_pdf" "letterhead" {
+  header           = "Statement"
+  content          = "Some statement contents"
+  filename         = "./statement.pdf"
+  letterhead_image = "./letterhead.png"
+  header_text      = "ACME Corporation - 1 Main St, Seattle WA"
+  footer_text      = "ACME Corporation is a registered trademark of ACME Holdings."
+  page_numbers     = true
+}
+
+resource "mailform_pdf" "windowed" {
+  header   = "Invoice"
+  content  = "Some invoice contents"
+  filename = "./invoice.pdf"
+  address_window {
+    to_name        = "A name"
+    to_address_1   = "Address 1"
+    to_city        = "Seattle"
+    to_state       = "WA"
+    to_postcode    = "00000"
+    to_country     = "US"
+    from_name      = "My name"
+    from_address_1 = "My Address 1"
+    from_city      = "Dallas"
+    from_state     = "TX"
+    from_postcode  = "00000"
+    from_country   = "US"
+  }
+}
+
+locals {
+  invoice_lines = csvdecode(file("./invoice.csv"))
+}
+
+resource "mailform_pdf" "invoice" {
+  header   = "Invoice #1001"
+  content  = "Thank you for your business."
+  filename = "./invoice_1001.pdf"
+  table {
+    column {
+      header = "Description"
+    }
+    column {
+      header    = "Amount"
+      width     = 40
+      alignment = "right"
+    }
+    rows   = [for line in local.invoice_lines : [line.description, line.amount]]
+    totals = ["Total", format("%.2f", sum([for line in local.invoice_lines : tonumber(line.amount)]))]
+    zebra  = true
+  }
+}
+
+resource "mailform_pdf" "mailer" {
+  image_filename = "./myimage.jpg"
+  filename       = "./mailer.pdf"
+  page_size      = "Postcard6x9"
+  orientation    = "landscape"
+  qr_code {
+    payload = "https://example.com/respond"
+    x       = 190
+    y       = 110
+    size    = 30
+  }
+  barcode {
+    payload = "TRACK-0001"
+    x       = 10
+    y       = 135
+    width   = 50
+    height  = 8
+  }
+}
+
+resource "mailform_pdf" "photos" {
+  images      = ["./front.webp", "./back.tiff"]
+  filename    = "./photos.pdf"
+  image_fit   = "contain"
+  auto_rotate = true
+  margins {
+    top    = 5
+    right  = 5
+    bottom = 5
+    left   = 5
+  }
+}
+
+output "photos_page_count" {
+  value = mailform_pdf.photos.page_count
+}
+
+resource "mailform_pdf" "agreement" {
+  header   = "Service Agreement"
+  content  = "The parties agree to the terms below."
+  filename = "./agreement.pdf"
+  signature {
+    image = "./signature.png"
+    x     = 20
+    y     = 200
+    width = 60
+    name  = "Jane Doe"
+    date  = "2023-03-01"
+  }
+  digital_signature {
+    certificate_file = "./signer.crt"
+    private_key_file = "./signer.key"
+    reason           = "Contract approval"
+  }
+}
+
+variable "pdf_owner_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "mailform_pdf" "statement" {
+  header         = "Account Statement"
+  content        = "Contains personal information"
+  filename       = "./statement.pdf"
+  owner_password = var.pdf_owner_password
+  permissions    = ["print"]
+}
+
+resource "mailform_pdf" "legal" {
+  source_file = "./vetted/terms.pdf"
+  filename    = "./terms.pdf"
+}
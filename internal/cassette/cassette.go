@@ -0,0 +1,378 @@
+// Package cassette records Mailform API interactions to a file and replays them.
+//
+// Recorded interactions are sanitized before they are written: API tokens are removed and the
+// addresses and check details of orders are replaced, so cassettes may be committed as test fixtures.
+// Uploaded files are recorded by their checksum only. Replayed requests must match the method, path,
+// query, sanitized form values and file checksums of a recorded request.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// ModeRecord sends requests upstream and saves each interaction
+	ModeRecord = "record"
+	// ModeReplay answers requests from the saved interactions without sending them upstream
+	ModeReplay = "replay"
+
+	// redacted replaces scrubbed values
+	redacted = "REDACTED"
+	// cassetteFileMode keeps cassettes private to the user recording them
+	cassetteFileMode = 0o600
+)
+
+var (
+	// scrubbedFormPrefixes are the form fields of orders holding addresses or check details
+	scrubbedFormPrefixes = []string{"to.", "from.", "bank_account", "check_name", "check_memo"}
+	// scrubbedObjects are the response objects holding addresses
+	scrubbedObjects = []string{"to", "from"}
+)
+
+// Request is a sanitized HTTP request
+type Request struct {
+	Method string              `json:"method"`
+	Path   string              `json:"path"`
+	Query  string              `json:"query,omitempty"`
+	Form   map[string][]string `json:"form,omitempty"`
+	// Files are the checksums of uploaded files keyed by form field
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// Response is a sanitized HTTP response
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the file interactions are recorded to
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Transport is an http.RoundTripper that records or replays interactions
+type Transport struct {
+	mode     string
+	path     string
+	upstream http.RoundTripper
+	secrets  []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	// played counts the replayed interactions of each sanitized request
+	played map[string]int
+}
+
+// New returns a transport using the cassette at path. In record mode requests are sent using
+// upstream and secrets, such as API tokens, are scrubbed from anything recorded.
+func New(path, mode string, upstream http.RoundTripper, secrets ...string) (*Transport, error) {
+	t := &Transport{
+		mode:     mode,
+		path:     path,
+		upstream: upstream,
+		played:   map[string]int{},
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			t.secrets = append(t.secrets, secret)
+		}
+	}
+
+	if mode != ModeRecord && mode != ModeReplay {
+		return nil, fmt.Errorf("cassette mode must be %q or %q, got %q", ModeRecord, ModeReplay, mode)
+	}
+	if t.upstream == nil {
+		t.upstream = http.DefaultTransport
+	}
+
+	// Terraform starts a provider for every command, so recordings are appended to the existing cassette
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeRecord {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &t.cassette)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+// RoundTrip records or replays a single interaction
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := t.sanitizeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == ModeReplay {
+		return t.replay(req, request)
+	}
+	return t.record(req, request)
+}
+
+// replay answers with the next interaction recorded for a request with the same method, path, query
+// and body. Once those are used up, the last of them is repeated so that additional reads see the
+// final state.
+func (t *Transport) replay(req *http.Request, request Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := request.Method + " " + request.Path
+	sameRoute := false
+	matches := []Interaction{}
+	for _, interaction := range t.cassette.Interactions {
+		if interaction.Request.Method != request.Method || interaction.Request.Path != request.Path {
+			continue
+		}
+		sameRoute = true
+		if interaction.Request.matches(request) {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 && sameRoute {
+		return nil, fmt.Errorf("cassette %s has no interaction for %s with a matching query and body", t.path, key)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("cassette %s has no interaction for %s", t.path, key)
+	}
+
+	// Requests are counted by their sanitized contents so differing bodies replay independently
+	played, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	i := t.played[string(played)]
+	if i >= len(matches) {
+		i = len(matches) - 1
+	}
+	t.played[string(played)]++
+
+	return newResponse(req, matches[i].Response), nil
+}
+
+// matches reports whether two sanitized requests have the same query, form values and files
+func (r Request) matches(other Request) bool {
+	return r.Query == other.Query &&
+		maps.EqualFunc(r.Form, other.Form, slices.Equal[string]) &&
+		maps.Equal(r.Files, other.Files)
+}
+
+// record sends a request upstream and saves the sanitized interaction
+func (t *Transport) record(req *http.Request, request Request) (*http.Response, error) {
+	resp, err := t.upstream.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: request,
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        t.sanitizeBody(body),
+		},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	// The cassette is saved after every interaction as providers are stopped without notice
+	content, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(t.path, append(content, '\n'), cassetteFileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// sanitizeRequest describes a request without its credentials, addresses or file contents. The
+// body is read and restored so the request can still be sent.
+func (t *Transport) sanitizeRequest(req *http.Request) (Request, error) {
+	request := Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  t.scrub(req.URL.RawQuery),
+	}
+	if req.Body == nil {
+		return request, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return request, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return request, err
+		}
+		request.Form = t.sanitizeForm(values)
+	case "multipart/form-data":
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)) + 1)
+		if err != nil {
+			return request, err
+		}
+		defer form.RemoveAll()
+
+		request.Form = t.sanitizeForm(form.Value)
+		request.Files = map[string]string{}
+		for field, headers := range form.File {
+			for _, header := range headers {
+				checksum, err := fileChecksum(header)
+				if err != nil {
+					return request, err
+				}
+				request.Files[field] = checksum
+			}
+		}
+	}
+
+	return request, nil
+}
+
+// sanitizeForm redacts addresses and check details from form values
+func (t *Transport) sanitizeForm(values map[string][]string) map[string][]string {
+	form := map[string][]string{}
+	for field, value := range values {
+		sanitized := make([]string, len(value))
+		for i, v := range value {
+			sanitized[i] = t.scrub(v)
+			if v != "" && hasAnyPrefix(field, scrubbedFormPrefixes) {
+				sanitized[i] = redacted
+			}
+		}
+		form[field] = sanitized
+	}
+	return form
+}
+
+// sanitizeBody redacts the addresses from JSON responses and secrets from any response
+func (t *Transport) sanitizeBody(body []byte) string {
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return t.scrub(string(body))
+	}
+
+	scrubAddresses(decoded)
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return t.scrub(string(body))
+	}
+	return t.scrub(string(encoded))
+}
+
+// scrub removes secrets from a value
+func (t *Transport) scrub(value string) string {
+	for _, secret := range t.secrets {
+		value = strings.ReplaceAll(value, secret, redacted)
+	}
+	return value
+}
+
+// scrubAddresses redacts every non-empty string within the address objects of a decoded JSON value
+func scrubAddresses(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if slices.Contains(scrubbedObjects, key) {
+				if address, ok := child.(map[string]any); ok {
+					for field, s := range address {
+						if s, ok := s.(string); ok && s != "" {
+							address[field] = redacted
+						}
+					}
+					continue
+				}
+			}
+			scrubAddresses(child)
+		}
+	case []any:
+		for _, child := range v {
+			scrubAddresses(child)
+		}
+	}
+}
+
+func fileChecksum(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func newResponse(req *http.Request, response Response) *http.Response {
+	header := http.Header{}
+	if response.ContentType != "" {
+		header.Set("Content-Type", response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode:    response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
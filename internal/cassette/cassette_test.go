@@ -0,0 +1,143 @@
+package cassette
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformtest"
+)
+
+const testToken = "secret-token"
+
+func testOrderInput(t *testing.T) mailform.OrderInput {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "order.pdf")
+	err := os.WriteFile(filePath, []byte("%PDF-1.3\n1 0 obj\n<</Type /Page>>\nendobj\n"), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return mailform.OrderInput{
+		FilePath:     filePath,
+		Service:      "USPS_FIRST_CLASS",
+		ToName:       "Jane Doe",
+		ToAddress1:   "1 Main St",
+		ToCity:       "Seattle",
+		ToState:      "WA",
+		ToPostcode:   "98101",
+		ToCountry:    "US",
+		FromName:     "John Doe",
+		FromAddress1: "2 Main St",
+		FromCity:     "Seattle",
+		FromState:    "WA",
+		FromPostcode: "98101",
+		FromCountry:  "US",
+		CheckMemo:    "Rent",
+	}
+}
+
+// testClient returns a client sending requests to target using transport
+func testClient(t *testing.T, target string, transport http.RoundTripper) *mailformapi.Client {
+	t.Helper()
+
+	client, err := mailformapi.New(&mailformapi.Config{Token: testToken, BaseURL: target, Transport: transport})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return client
+}
+
+func TestTransportRecordReplay(t *testing.T) {
+	server := mailformtest.NewServer(testToken)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := New(path, ModeRecord, nil, testToken)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client := testClient(t, server.URL, recorder)
+
+	created, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	id := created.Data.ID
+	if err := server.SetState(id, mailform.StatusFulfilled); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := client.GetOrder(id); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := client.GetOrder("ord_missing"); err == nil {
+		t.Fatal("expected order_not_found")
+	}
+	server.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, secret := range []string{testToken, "Jane Doe", "1 Main St", "Rent", "%PDF"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("expected %q to be scrubbed from the cassette", secret)
+		}
+	}
+	if !strings.Contains(string(content), `"to.name": [`) || !strings.Contains(string(content), `"file": "`) {
+		t.Errorf("expected form fields and file checksums to be recorded, got %s", content)
+	}
+
+	// The server is closed, so responses can only come from the cassette
+	replayer, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client = testClient(t, server.URL, replayer)
+
+	replayed, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if replayed.Data.ID != id || replayed.Data.Lineitems[0].To.Name != redacted {
+		t.Errorf("expected order %s with a scrubbed recipient, got %+v", id, replayed.Data)
+	}
+	changed := testOrderInput(t)
+	changed.Service = "USPS_PRIORITY"
+	if _, err := client.CreateOrder(changed); err == nil || !strings.Contains(err.Error(), "has no interaction for POST /orders with a matching query and body") {
+		t.Errorf("expected an order with a different body to fail, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		order, err := client.GetOrder(id)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if order.Data.State != mailform.StatusFulfilled {
+			t.Errorf("expected %s, got %s", mailform.StatusFulfilled, order.Data.State)
+		}
+	}
+	if _, err := client.GetOrder("ord_missing"); err == nil || err.Error() != "order_not_found" {
+		t.Errorf("expected order_not_found, got %v", err)
+	}
+	if _, err := client.GetOrder("ord_unrecorded"); err == nil || !strings.Contains(err.Error(), "has no interaction for GET /orders/ord_unrecorded") {
+		t.Errorf("expected unrecorded request to fail, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	if _, err := New(path, "rewind", nil); err == nil {
+		t.Error("expected unknown mode to be rejected")
+	}
+	if _, err := New(path, ModeReplay, nil); err == nil {
+		t.Error("expected replaying a missing cassette to fail")
+	}
+	if _, err := New(path, ModeRecord, nil); err != nil {
+		t.Errorf("expected recording to a new cassette, got %s", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package mailformapi is a Mailform API client that sends requests through a custom transport.
+//
+// It makes the same requests as go-mailform and returns its types, but go-mailform builds its own
+// HTTP client, so its requests cannot be logged, recorded or replayed without a proxy. This package
+// can be removed once go-mailform accepts a transport.
+package mailformapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+)
+
+// ordersEndpoint is the path of orders relative to the base URL
+const ordersEndpoint = "/orders"
+
+// ErrNilConfig is returned when a nil config is passed to New
+var ErrNilConfig = errors.New("config cannot be nil")
+
+// Config is the configuration used to communicate with the Mailform API
+type Config struct {
+	Token string
+	// BaseURL defaults to mailform.DefaultBaseURL
+	BaseURL string
+	// Timeout defaults to mailform.DefaultTimeout
+	Timeout time.Duration
+	// Transport sends requests, defaulting to http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// Client is a Mailform API client
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// New returns a Mailform API client
+func New(c *Config) (*Client, error) {
+	if c == nil {
+		return nil, ErrNilConfig
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Transport: c.Transport,
+			Timeout:   c.Timeout,
+		},
+		baseURL: strings.TrimSuffix(c.BaseURL, "/"),
+		token:   c.Token,
+	}
+	if client.baseURL == "" {
+		client.baseURL = mailform.DefaultBaseURL
+	}
+	if client.httpClient.Timeout == 0 {
+		client.httpClient.Timeout = mailform.DefaultTimeout
+	}
+
+	return client, nil
+}
+
+// CreateOrder validates and creates an order. Orders with a file path upload the file as multipart
+// form data, others are sent as a URL encoded form.
+func (c *Client) CreateOrder(o mailform.OrderInput) (*mailform.Order, error) {
+	err := o.Validate()
+	if err != nil {
+		return &mailform.Order{}, err
+	}
+
+	body, contentType, err := orderForm(o)
+	if err != nil {
+		return &mailform.Order{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+ordersEndpoint, body)
+	if err != nil {
+		return &mailform.Order{}, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return c.do(req)
+}
+
+// GetOrder gets an order by ID
+func (c *Client) GetOrder(id string) (*mailform.Order, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+ordersEndpoint+"/"+url.PathEscape(id), nil)
+	if err != nil {
+		return &mailform.Order{}, err
+	}
+
+	return c.do(req)
+}
+
+// do sends an authenticated request and decodes the order it responds with
+func (c *Client) do(req *http.Request) (*mailform.Order, error) {
+	order := &mailform.Order{}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return order, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return order, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return order, newError(strconv.Itoa(http.StatusUnauthorized), "unauthorized")
+	}
+
+	mailformErr := &mailform.ErrMailform{}
+	if resp.StatusCode >= http.StatusBadRequest {
+		// Error bodies are not always JSON, so the status and body stand in for a missing code or message
+		_ = json.Unmarshal(body, mailformErr)
+		if mailformErr.Err.Code == "" {
+			mailformErr.Err.Code = strconv.Itoa(resp.StatusCode)
+		}
+		if mailformErr.Err.Message == "" && mailformErr.Detail == "" {
+			mailformErr.Err.Message = string(body)
+		}
+		return order, mailformErr
+	}
+
+	// Mailform also reports errors with a successful status
+	err = json.Unmarshal(body, mailformErr)
+	if err != nil {
+		return order, err
+	}
+	if mailformErr.Err.Message != "" {
+		return order, mailformErr
+	}
+
+	err = json.Unmarshal(body, order)
+	if err != nil {
+		return order, err
+	}
+	return order, nil
+}
+
+// orderForm encodes the form of an order, returning its body and content type
+func orderForm(o mailform.OrderInput) (io.Reader, string, error) {
+	formData := o.FormData()
+
+	if o.FilePath == "" {
+		values := url.Values{}
+		for field, value := range formData {
+			values.Set(field, value)
+		}
+		return strings.NewReader(values.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, value := range formData {
+		err := writer.WriteField(field, value)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	file, err := os.Open(o.FilePath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	part, err := writer.CreateFormFile("file", filepath.Base(o.FilePath))
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", o.FilePath, err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, "", err
+	}
+	return &body, writer.FormDataContentType(), nil
+}
+
+func newError(code, message string) *mailform.ErrMailform {
+	mailformErr := &mailform.ErrMailform{}
+	mailformErr.Err.Code = code
+	mailformErr.Err.Message = message
+	return mailformErr
+}
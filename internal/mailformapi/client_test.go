@@ -0,0 +1,131 @@
+package mailformapi
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformtest"
+)
+
+const testToken = "test-token"
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func testOrderInput(t *testing.T) mailform.OrderInput {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "order.pdf")
+	err := os.WriteFile(filePath, []byte("%PDF-1.3\n1 0 obj\n<</Type /Page>>\nendobj\n"), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return mailform.OrderInput{
+		FilePath:          filePath,
+		CustomerReference: "ref",
+		Service:           "USPS_FIRST_CLASS",
+		ToName:            "Jane Doe",
+		ToAddress1:        "1 Main St",
+		ToCity:            "Seattle",
+		ToState:           "WA",
+		ToPostcode:        "98101",
+		ToCountry:         "US",
+		FromName:          "John Doe",
+		FromAddress1:      "2 Main St",
+		FromCity:          "Seattle",
+		FromState:         "WA",
+		FromPostcode:      "98101",
+		FromCountry:       "US",
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := mailformtest.NewServer(testToken)
+	defer s.Close()
+	transport := &countingTransport{}
+	client, err := New(&Config{Token: testToken, BaseURL: s.URL + "/", Transport: transport})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	created, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if created.Data.State != mailform.StatusQueued || created.Data.CustomerReference != "ref" {
+		t.Errorf("expected a queued order, got %+v", created.Data)
+	}
+	if !strings.HasPrefix(string(s.Upload(created.Data.ID)), "%PDF-") {
+		t.Error("expected the PDF to be uploaded")
+	}
+
+	input := testOrderInput(t)
+	input.FilePath = ""
+	input.URL = "https://example.com/letter.pdf"
+	byURL, err := client.CreateOrder(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	order, err := client.GetOrder(byURL.Data.ID)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if order.Data.ID != byURL.Data.ID {
+		t.Errorf("expected order %s, got %s", byURL.Data.ID, order.Data.ID)
+	}
+	if requests := transport.requests.Load(); requests != 3 {
+		t.Errorf("expected 3 requests through the transport, got %d", requests)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	s := mailformtest.NewServer(testToken)
+	defer s.Close()
+	client, err := New(&Config{Token: testToken, BaseURL: s.URL})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var mailformErr *mailform.ErrMailform
+	if _, err := client.GetOrder("ord_missing"); !errors.As(err, &mailformErr) || mailformErr.Err.Code != "order_not_found" {
+		t.Errorf("expected order_not_found, got %v", err)
+	}
+
+	s.FailNext(http.StatusInternalServerError, "unknown_error", "erroroccurred")
+	if _, err := client.GetOrder("ord_missing"); !errors.As(err, &mailformErr) || err.Error() != "erroroccurred" {
+		t.Errorf("expected erroroccurred, got %v", err)
+	}
+
+	input := testOrderInput(t)
+	input.Service = "CARRIER_PIGEON"
+	if _, err := client.CreateOrder(input); err == nil || s.Orders() != 0 {
+		t.Errorf("expected an invalid order to be rejected before sending, got %v", err)
+	}
+
+	unauthorized, err := New(&Config{Token: "wrong", BaseURL: s.URL})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := unauthorized.GetOrder("ord_missing"); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+
+	if _, err := New(nil); !errors.Is(err, ErrNilConfig) {
+		t.Errorf("expected ErrNilConfig, got %v", err)
+	}
+}
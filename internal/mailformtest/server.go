@@ -0,0 +1,406 @@
+// Package mailformtest provides an in-process fake of the Mailform API for tests.
+//
+// The fake implements the endpoints used by the provider: order creation with a multipart
+// upload or URL and order retrieval. Orders start queued and move through
+// the fulfillment states when advanced by the test or, with AdvanceOnRead, each time they are read.
+package mailformtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/postal"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// ordersPath is the prefix of every order endpoint
+	ordersPath = "/orders"
+	// pagePrice and servicePrice are what the fake charges in cents. They are round numbers
+	// for asserting totals rather than Mailform's prices.
+	pagePrice    = 25
+	servicePrice = 100
+	// maxUploadSize is the largest multipart request the fake accepts
+	maxUploadSize = 32 << 20
+)
+
+var (
+	// states are the fulfillment states an order moves through, in order
+	states = []string{mailform.StatusQueued, mailform.StatusAwaitingFulfillment, mailform.StatusFulfilled}
+
+	pagePattern = regexp.MustCompile(`/Type\s*/Page\b`)
+
+	// requiredFields are the form fields the API rejects orders without
+	requiredFields = []string{
+		"service",
+		"to.name", "to.address1", "to.city", "to.state", "to.postcode", "to.country",
+		"from.name", "from.address1", "from.city", "from.state", "from.postcode", "from.country",
+	}
+)
+
+// Address is the sender or recipient of a line item
+type Address struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Address1     string `json:"address1"`
+	Address2     string `json:"address2"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Postcode     string `json:"postcode"`
+	Country      string `json:"country"`
+	Formatted    string `json:"formatted"`
+}
+
+// Price is a single charge of a line item in cents
+type Price struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// LineItem is a single piece of mail within an order
+type LineItem struct {
+	ID        string  `json:"id"`
+	Pagecount int     `json:"pagecount"`
+	To        Address `json:"to"`
+	From      Address `json:"from"`
+	Simplex   bool    `json:"simplex"`
+	Color     bool    `json:"color"`
+	Service   string  `json:"service"`
+	Pricing   []Price `json:"pricing"`
+}
+
+// Order is an order as returned by the API
+type Order struct {
+	Object             string     `json:"object"`
+	ID                 string     `json:"id"`
+	Created            time.Time  `json:"created"`
+	Total              int        `json:"total"`
+	Modified           time.Time  `json:"modified"`
+	Webhook            string     `json:"webhook"`
+	Lineitems          []LineItem `json:"lineitems"`
+	Account            string     `json:"account"`
+	CustomerReference  string     `json:"customer_reference"`
+	Channel            string     `json:"channel"`
+	TestMode           bool       `json:"test_mode"`
+	State              string     `json:"state"`
+	Cancelled          time.Time  `json:"cancelled"`
+	CancellationReason string     `json:"cancellation_reason"`
+
+	// file is the uploaded PDF, which the API does not return
+	file []byte
+}
+
+// Server is a fake Mailform API
+type Server struct {
+	*httptest.Server
+
+	// Token is the API token requests must authenticate with
+	Token string
+	// AdvanceOnRead moves an order to its next fulfillment state each time it is read
+	AdvanceOnRead bool
+
+	mu       sync.Mutex
+	orders   map[string]*Order
+	sequence int
+	failures []failure
+}
+
+// failure is an error response returned in place of the next request
+type failure struct {
+	status  int
+	code    string
+	message string
+}
+
+// NewServer starts a fake Mailform API accepting token. Callers must Close the server when done.
+func NewServer(token string) *Server {
+	s := &Server{
+		Token:  token,
+		orders: map[string]*Order{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ordersPath, s.handleOrders)
+	mux.HandleFunc(ordersPath+"/", s.handleOrder)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+
+	return s
+}
+
+// Order returns a copy of an order
+func (s *Server) Order(id string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// Upload returns the PDF uploaded with an order
+func (s *Server) Upload(id string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if order, ok := s.orders[id]; ok {
+		return order.file
+	}
+	return nil
+}
+
+// Orders returns the number of orders created
+func (s *Server) Orders() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.orders)
+}
+
+// SetState moves an order to a state
+func (s *Server) SetState(id, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return fmt.Errorf("order %q not found", id)
+	}
+	if state != mailform.StatusCancelled && !slices.Contains(states, state) {
+		return fmt.Errorf("unknown state %q", state)
+	}
+	order.State = state
+	order.Modified = time.Now().UTC()
+	return nil
+}
+
+// Cancel cancels an order as Mailform does when an order cannot be fulfilled
+func (s *Server) Cancel(id, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return fmt.Errorf("order %q not found", id)
+	}
+	cancel(order, reason)
+	return nil
+}
+
+// FailNext responds to the next request with an error instead of handling it
+func (s *Server) FailNext(status int, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure{status: status, code: code, message: message})
+}
+
+// authenticate rejects requests without the bearer token and returns injected failures
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "unauthorized")
+			return
+		}
+
+		s.mu.Lock()
+		injected, ok := failure{}, len(s.failures) > 0
+		if ok {
+			injected, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+		if ok {
+			writeError(w, injected.status, injected.code, injected.message)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleOrders creates orders
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method_not_allowed")
+		return
+	}
+
+	// Orders for a URL are sent as a plain form
+	err := r.ParseMultipartForm(maxUploadSize)
+	if err == http.ErrNotMultipart {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	for _, field := range requiredFields {
+		if r.FormValue(field) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("missing_%s", strings.ReplaceAll(field, ".", "_")))
+			return
+		}
+	}
+	service := r.FormValue("service")
+	if !slices.Contains(mailform.ServiceCodes, service) {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid_service")
+		return
+	}
+
+	var content []byte
+	pages := 1
+	if file, _, err := r.FormFile("file"); err == nil {
+		content, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		pages = len(pagePattern.FindAll(content, -1))
+	} else if r.FormValue("url") == "" {
+		// Mailform reports a missing file with a generic code
+		writeError(w, http.StatusBadRequest, "erroroccurred", "no_file_uploaded")
+		return
+	}
+
+	s.mu.Lock()
+	s.sequence++
+	now := time.Now().UTC()
+	order := &Order{
+		Object:            "order",
+		ID:                fmt.Sprintf("ord_%06d", s.sequence),
+		Created:           now,
+		Modified:          now,
+		Webhook:           r.FormValue("webhook"),
+		Account:           "acct_test",
+		CustomerReference: r.FormValue("customer_reference"),
+		Channel:           "api",
+		TestMode:          true,
+		State:             mailform.StatusQueued,
+		file:              content,
+	}
+	order.Lineitems = []LineItem{{
+		ID:        fmt.Sprintf("li_%06d", s.sequence),
+		Pagecount: pages,
+		To:        formAddress(r, "to"),
+		From:      formAddress(r, "from"),
+		Simplex:   r.FormValue("simplex") == "true",
+		Color:     r.FormValue("color") == "true",
+		Service:   service,
+		Pricing: []Price{
+			{Type: "pages", Value: pages * pagePrice},
+			{Type: "service", Value: servicePrice},
+		},
+	}}
+	order.Total = pages*pagePrice + servicePrice
+	s.orders[order.ID] = order
+	response := *order
+	s.mu.Unlock()
+
+	writeOrder(w, http.StatusCreated, response)
+}
+
+// handleOrder gets existing orders
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, ordersPath+"/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "order_not_found", "order_not_found")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		if s.AdvanceOnRead {
+			advance(order)
+		}
+		writeOrder(w, http.StatusOK, *order)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method_not_allowed")
+	}
+}
+
+// advance moves an order to its next fulfillment state. Cancelled and fulfilled orders do not change.
+func advance(order *Order) {
+	i := slices.Index(states, order.State)
+	if i < 0 || i == len(states)-1 {
+		return
+	}
+	order.State = states[i+1]
+	order.Modified = time.Now().UTC()
+}
+
+func cancel(order *Order, reason string) {
+	now := time.Now().UTC()
+	order.State = mailform.StatusCancelled
+	order.Cancelled = now
+	order.Modified = now
+	order.CancellationReason = reason
+}
+
+// formAddress reads the address fields of an order with the to or from prefix
+func formAddress(r *http.Request, prefix string) Address {
+	address := Address{
+		Name:         r.FormValue(prefix + ".name"),
+		Organization: r.FormValue(prefix + ".organization"),
+		Address1:     r.FormValue(prefix + ".address1"),
+		Address2:     r.FormValue(prefix + ".address2"),
+		City:         r.FormValue(prefix + ".city"),
+		State:        r.FormValue(prefix + ".state"),
+		Postcode:     r.FormValue(prefix + ".postcode"),
+		Country:      r.FormValue(prefix + ".country"),
+	}
+
+	address.Formatted = postal.Format(postal.Address{
+		Name:         address.Name,
+		Organization: address.Organization,
+		Address1:     address.Address1,
+		Address2:     address.Address2,
+		City:         address.City,
+		State:        address.State,
+		Postcode:     address.Postcode,
+		Country:      address.Country,
+	})
+
+	return address
+}
+
+func writeOrder(w http.ResponseWriter, status int, order Order) {
+	writeJSON(w, status, map[string]any{
+		"success": true,
+		"data":    order,
+	})
+}
+
+// writeError responds in the format the API uses for errors
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,167 @@
+package mailformtest
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/circa10a/go-mailform"
+)
+
+const testToken = "test-token"
+
+func testClient(t *testing.T, s *Server, token string) *mailform.Client {
+	t.Helper()
+
+	client, err := mailform.New(&mailform.Config{Token: token, BaseURL: s.URL})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return client
+}
+
+func testOrderInput(t *testing.T) mailform.OrderInput {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "order.pdf")
+	err := os.WriteFile(filePath, []byte("%PDF-1.3\n1 0 obj\n<</Type /Page>>\nendobj\n2 0 obj\n<</Type /Page>>\nendobj\n"), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return mailform.OrderInput{
+		FilePath:          filePath,
+		CustomerReference: "ref",
+		Service:           "USPS_FIRST_CLASS",
+		ToName:            "Jane Doe",
+		ToAddress1:        "1 Main St",
+		ToCity:            "Seattle",
+		ToState:           "WA",
+		ToPostcode:        "98101",
+		ToCountry:         "US",
+		FromName:          "John Doe",
+		FromAddress1:      "2 Main St",
+		FromCity:          "Seattle",
+		FromState:         "WA",
+		FromPostcode:      "98101",
+		FromCountry:       "US",
+	}
+}
+
+func TestServerCreateOrder(t *testing.T) {
+	s := NewServer(testToken)
+	defer s.Close()
+	client := testClient(t, s, testToken)
+
+	created, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if created.Data.State != mailform.StatusQueued {
+		t.Errorf("expected %s, got %s", mailform.StatusQueued, created.Data.State)
+	}
+	if pages := created.Data.Lineitems[0].Pagecount; pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+	if created.Data.Total != 2*pagePrice+servicePrice {
+		t.Errorf("expected total %d, got %d", 2*pagePrice+servicePrice, created.Data.Total)
+	}
+	if !strings.HasPrefix(string(s.Upload(created.Data.ID)), "%PDF-") {
+		t.Error("expected the uploaded PDF to be kept")
+	}
+
+	input := testOrderInput(t)
+	input.FilePath = ""
+	input.URL = "https://example.com/letter.pdf"
+	byURL, err := client.CreateOrder(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if byURL.Data.Lineitems[0].To.Formatted != "Jane Doe\n1 Main St\nSeattle, WA 98101\nUS" {
+		t.Errorf("unexpected formatted address %q", byURL.Data.Lineitems[0].To.Formatted)
+	}
+	if s.Orders() != 2 {
+		t.Errorf("expected 2 orders, got %d", s.Orders())
+	}
+
+	input.URL = ""
+	var mailformErr *mailform.ErrMailform
+	if _, err := client.CreateOrder(input); !errors.As(err, &mailformErr) || mailformErr.Err.Message != "no_file_uploaded" {
+		t.Errorf("expected no_file_uploaded, got %v", err)
+	}
+}
+
+func TestServerGetOrder(t *testing.T) {
+	s := NewServer(testToken)
+	defer s.Close()
+	client := testClient(t, s, testToken)
+
+	created, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	id := created.Data.ID
+
+	s.AdvanceOnRead = true
+	for _, expected := range []string{mailform.StatusAwaitingFulfillment, mailform.StatusFulfilled, mailform.StatusFulfilled} {
+		order, err := client.GetOrder(id)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if order.Data.State != expected {
+			t.Errorf("expected %s, got %s", expected, order.Data.State)
+		}
+	}
+
+	if _, err := client.GetOrder("ord_missing"); err == nil || err.Error() != "order_not_found" {
+		t.Errorf("expected order_not_found, got %v", err)
+	}
+
+	s.FailNext(http.StatusInternalServerError, "erroroccurred", "unknown_error")
+	if _, err := client.GetOrder(id); err == nil || err.Error() != "unknown_error" {
+		t.Errorf("expected injected failure, got %v", err)
+	}
+	if _, err := client.GetOrder(id); err != nil {
+		t.Errorf("expected injected failure to apply once, got %v", err)
+	}
+
+	if _, err := testClient(t, s, "wrong").GetOrder(id); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestServerCancelOrder(t *testing.T) {
+	s := NewServer(testToken)
+	defer s.Close()
+	client := testClient(t, s, testToken)
+
+	created, err := client.CreateOrder(testOrderInput(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	id := created.Data.ID
+
+	if err := s.Cancel(id, "address_undeliverable"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	order, _ := s.Order(id)
+	if order.State != mailform.StatusCancelled || order.CancellationReason != "address_undeliverable" || order.Cancelled.IsZero() {
+		t.Errorf("expected cancelled order, got %+v", order)
+	}
+
+	// Cancelled orders are not fulfilled when read
+	s.AdvanceOnRead = true
+	if got, _ := client.GetOrder(id); got.Data.State != mailform.StatusCancelled {
+		t.Errorf("expected %s, got %s", mailform.StatusCancelled, got.Data.State)
+	}
+
+	if err := s.Cancel("ord_missing", "typo"); err == nil {
+		t.Error("expected unknown order to be rejected")
+	}
+	if err := s.SetState(id, "lost"); err == nil {
+		t.Error("expected unknown state to be rejected")
+	}
+}
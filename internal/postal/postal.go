@@ -0,0 +1,41 @@
+// Package postal formats postal addresses as Mailform does for the to and from addresses of an order.
+//
+// The provider's format_address function and the fake API in mailformtest both use Format, so the
+// fake cannot drift from the function it is used to test. The format follows the to_formatted
+// attribute of orders but has not been checked against responses recorded from the Mailform API.
+package postal
+
+import (
+	"strings"
+)
+
+// Address is a postal address as sent to Mailform with an order
+type Address struct {
+	Name         string
+	Organization string
+	Address1     string
+	Address2     string
+	City         string
+	State        string
+	Postcode     string
+	Country      string
+}
+
+// Format returns the name, organization, address lines, "city, state postcode" and country on
+// separate lines. Empty lines are left out, as are the separators of an incomplete city line.
+func Format(a Address) string {
+	locality := strings.TrimSpace(a.State + " " + a.Postcode)
+	if a.City != "" && locality != "" {
+		locality = a.City + ", " + locality
+	} else if a.City != "" {
+		locality = a.City
+	}
+
+	lines := []string{}
+	for _, line := range []string{a.Name, a.Organization, a.Address1, a.Address2, locality, a.Country} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,60 @@
+package postal
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  Address
+		expected string
+	}{
+		{
+			name: "full",
+			address: Address{
+				Name:         "Jane Doe",
+				Organization: "Example Co.",
+				Address1:     "1 Main St",
+				Address2:     "Suite 100",
+				City:         "Seattle",
+				State:        "WA",
+				Postcode:     "98101",
+				Country:      "US",
+			},
+			expected: "Jane Doe\nExample Co.\n1 Main St\nSuite 100\nSeattle, WA 98101\nUS",
+		},
+		{
+			name:     "optional lines",
+			address:  Address{Name: "Jane Doe", Address1: "1 Main St", City: "Seattle", State: "WA", Postcode: "98101", Country: "US"},
+			expected: "Jane Doe\n1 Main St\nSeattle, WA 98101\nUS",
+		},
+		{
+			name:     "name only",
+			address:  Address{Name: "Jane Doe"},
+			expected: "Jane Doe",
+		},
+		{
+			name:     "city only",
+			address:  Address{Name: "Jane Doe", City: "Seattle"},
+			expected: "Jane Doe\nSeattle",
+		},
+		{
+			name:     "no city",
+			address:  Address{Name: "Jane Doe", Postcode: "SW1A 1AA", Country: "GB"},
+			expected: "Jane Doe\nSW1A 1AA\nGB",
+		},
+		{
+			name:     "empty",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := Format(test.address); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
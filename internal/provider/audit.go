@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// auditLogFileMode keeps the recipients of orders private to the user running Terraform
+	auditLogFileMode = 0o600
+
+	// Audit events
+	auditEventCreate      = "create"
+	auditEventStateChange = "state_change"
+	auditEventDelete      = "delete"
+)
+
+// auditRecord is a line of the audit log
+type auditRecord struct {
+	Timestamp         time.Time `json:"timestamp"`
+	Event             string    `json:"event"`
+	OrderID           string    `json:"order_id"`
+	CustomerReference string    `json:"customer_reference,omitempty"`
+	// Recipient is the formatted address of the recipient
+	Recipient string `json:"recipient,omitempty"`
+	Service   string `json:"service,omitempty"`
+	// Total is the cost of the order in cents
+	Total         int    `json:"total"`
+	PDFSHA256     string `json:"pdf_sha256,omitempty"`
+	PDFURL        string `json:"pdf_url,omitempty"`
+	State         string `json:"state,omitempty"`
+	PreviousState string `json:"previous_state,omitempty"`
+}
+
+// auditLog appends a JSON Lines record of every order the provider creates, sees change state or deletes
+type auditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newAuditLog(path string) *auditLog {
+	return &auditLog{
+		path: path,
+	}
+}
+
+// write appends a record to the audit log
+func (a *auditLog) write(record auditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, auditLogFileMode)
+	if err != nil {
+		return err
+	}
+	// Each record is a single write, so concurrent providers appending to the same file do not interleave
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// auditOrder records an event for the order in d if the provider configured with m has an audit log.
+// Failing to write the record does not fail the operation, which has already happened.
+func auditOrder(d *schema.ResourceData, m any, event, previousState string) diag.Diagnostics {
+	// Inputs such as service are only known to the resource, not the data source
+	getString := func(key string) string {
+		s, _ := d.Get(key).(string)
+		return s
+	}
+	total, _ := d.Get("total").(int)
+
+	return writeAuditRecord(m, auditRecord{
+		Event:             event,
+		OrderID:           d.Id(),
+		CustomerReference: getString("customer_reference"),
+		Recipient:         getString("lineitems.0.to_formatted"),
+		Service:           getString("service"),
+		Total:             total,
+		PDFSHA256:         getString("pdf_sha256"),
+		PDFURL:            getString("pdf_url"),
+		State:             getString("state"),
+		PreviousState:     previousState,
+	})
+}
+
+// auditCreatedOrder records the creation of an order from the response to creating it. It is written
+// before the order is read into d, so that orders are accounted for even if waiting for or reading them fails.
+func auditCreatedOrder(d *schema.ResourceData, m any, order *mailform.Order) diag.Diagnostics {
+	recipient := ""
+	if len(order.Data.Lineitems) > 0 {
+		recipient = order.Data.Lineitems[0].To.Formatted
+	}
+
+	return writeAuditRecord(m, auditRecord{
+		Event:             auditEventCreate,
+		OrderID:           order.Data.ID,
+		CustomerReference: order.Data.CustomerReference,
+		Recipient:         recipient,
+		Service:           d.Get("service").(string),
+		Total:             order.Data.Total,
+		PDFSHA256:         d.Get("pdf_sha256").(string),
+		PDFURL:            d.Get("pdf_url").(string),
+		State:             order.Data.State,
+	})
+}
+
+// writeAuditRecord appends a record timestamped now to the audit log of the provider configured with m, if it has one
+func writeAuditRecord(m any, record auditRecord) diag.Diagnostics {
+	providerConfig := m.(map[string]interface{})
+	audit, ok := providerConfig["audit_log"].(*auditLog)
+	if !ok {
+		return nil
+	}
+
+	record.Timestamp = time.Now().UTC()
+	if err := audit.write(record); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Unable to write the audit log",
+			Detail:   fmt.Sprintf("The %s of order %s was not recorded in %s: %s", record.Event, record.OrderID, audit.path, err),
+		}}
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	meta, err := newProviderConfig(context.Background(), testAccAPIToken, testAccServer.URL, path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	pdf := schema.TestResourceDataRaw(t, resourcePDF().Schema, map[string]any{"header": "Invoice", "content": "Amount due: $42"})
+	content, err := renderPDFContent(expandPDFOptions(pdf))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	checksum := sha256.Sum256(content)
+
+	d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{
+		"pdf_content_base64": base64.StdEncoding.EncodeToString(content),
+		"customer_reference": "invoice-42",
+		"service":            "USPS_FIRST_CLASS",
+		"to_name":            "Jane Doe",
+		"to_address_1":       "1 Main St",
+		"to_city":            "Seattle",
+		"to_state":           "WA",
+		"to_postcode":        "98101",
+		"to_country":         "US",
+		"from_name":          "John Doe",
+		"from_address_1":     "2 Main St",
+		"from_city":          "Dallas",
+		"from_state":         "TX",
+		"from_postcode":      "75001",
+		"from_country":       "US",
+	})
+	if diags := resourceMailformOrderCreate(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	// Unchanged states are not recorded
+	if diags := orderRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if err := testAccServer.SetState(d.Id(), mailform.StatusFulfilled); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diags := orderRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if diags := resourceMailformOrderDelete(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != auditLogFileMode {
+		t.Errorf("expected mode %o, got %o", auditLogFileMode, mode)
+	}
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	records := []auditRecord{}
+	for _, line := range bytes.Split(bytes.TrimSpace(log), []byte("\n")) {
+		var record auditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected create, state change and delete records, got %s", log)
+	}
+
+	expected := []struct{ event, state, previousState string }{
+		{auditEventCreate, mailform.StatusQueued, ""},
+		{auditEventStateChange, mailform.StatusFulfilled, mailform.StatusQueued},
+		{auditEventDelete, mailform.StatusFulfilled, ""},
+	}
+	for i, record := range records {
+		if record.Event != expected[i].event || record.State != expected[i].state || record.PreviousState != expected[i].previousState {
+			t.Errorf("expected %s record in state %s from %q, got %+v", expected[i].event, expected[i].state, expected[i].previousState, record)
+		}
+		if record.OrderID == "" || record.Timestamp.IsZero() || record.CustomerReference != "invoice-42" || record.Service != "USPS_FIRST_CLASS" || record.Total == 0 {
+			t.Errorf("expected order details, got %+v", record)
+		}
+		if record.Recipient != "Jane Doe\n1 Main St\nSeattle, WA 98101\nUS" {
+			t.Errorf("expected formatted recipient, got %q", record.Recipient)
+		}
+		if record.PDFSHA256 != hex.EncodeToString(checksum[:]) {
+			t.Errorf("expected checksum of the uploaded PDF, got %q", record.PDFSHA256)
+		}
+	}
+}
+
+func TestAuditLogWaitFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	meta, err := newProviderConfig(context.Background(), testAccAPIToken, testAccServer.URL, path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{
+		"pdf_url":              "https://example.com/letter.pdf",
+		"wait_until_fulfilled": true,
+		"service":              "USPS_FIRST_CLASS",
+		"to_name":              "Jane Doe",
+		"to_address_1":         "1 Main St",
+		"to_city":              "Seattle",
+		"to_state":             "WA",
+		"to_postcode":          "98101",
+		"to_country":           "US",
+		"from_name":            "John Doe",
+		"from_address_1":       "2 Main St",
+		"from_city":            "Dallas",
+		"from_state":           "TX",
+		"from_postcode":        "75001",
+		"from_country":         "US",
+	})
+	// The order is placed, but waiting for it stops at once
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if diags := resourceMailformOrderCreate(ctx, d, meta); !diags.HasError() {
+		t.Fatal("expected waiting for the order to fail")
+	}
+
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var record auditRecord
+	if err := json.Unmarshal(bytes.TrimSpace(log), &record); err != nil {
+		t.Fatalf("expected a single create record, got %s", log)
+	}
+	if record.Event != auditEventCreate || record.OrderID == "" || record.OrderID != d.Id() || record.State != mailform.StatusQueued {
+		t.Errorf("expected the created order to be recorded, got %+v", record)
+	}
+	if record.PDFURL != "https://example.com/letter.pdf" || record.Recipient != "Jane Doe\n1 Main St\nSeattle, WA 98101\nUS" {
+		t.Errorf("expected order details, got %+v", record)
+	}
+}
+
+func TestAuditLogUnwritable(t *testing.T) {
+	meta := map[string]any{"audit_log": newAuditLog(t.TempDir())}
+	d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{})
+	d.SetId("ord_000001")
+	if err := d.Set("state", mailform.StatusCancelled); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diags := resourceMailformOrderDelete(context.Background(), d, meta)
+	if len(diags) != 1 || diags.HasError() {
+		t.Errorf("expected a warning when the audit log cannot be written, got %v", diags)
+	}
+	if d.Id() != "" {
+		t.Error("expected the order to be removed from state")
+	}
+}
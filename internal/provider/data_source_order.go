@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -175,22 +177,25 @@ func dataSourceOrder() *schema.Resource {
 }
 
 func orderRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	providerConfig := m.(map[string]interface{})
-	client := providerConfig["client"].(*mailform.Client)
+	return readOrder(orderLogContext(ctx, m, mailform.OrderInput{}), d, m)
+}
 
+// readOrder sets the state of an order, logging with the context of the operation reading it
+func readOrder(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	id := d.Get("id").(string)
-	order, err := client.GetOrder(id)
+	order, err := getOrder(ctx, m, d.Get("id").(string))
 	if err != nil {
 		// handle the case where the order does not exist and we gracefully SetID("") I guess.
 		// this allows the user to make decisions in tf code instead of having that shit just bail out.
-		if strings.Contains(err.Error(), "order_not_found") {
+		if isOrderNotFound(err) {
 			d.SetId("")
 			return diags
 		}
 		return diag.FromErr(err)
 	}
+	// Orders being created have the state they were created in, so changes while waiting are audited
+	previousState := d.Get("state").(string)
 
 	d.SetId(order.Data.ID)
 
@@ -246,9 +251,47 @@ func orderRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnost
 		return diag.FromErr(err)
 	}
 
+	if previousState != "" && previousState != order.Data.State {
+		diags = append(diags, auditOrder(d, m, auditEventStateChange, previousState)...)
+	}
+
 	return diags
 }
 
+// getOrder gets an order from the API, tracing and logging the request
+func getOrder(ctx context.Context, m any, id string) (*mailform.Order, error) {
+	providerConfig := m.(map[string]interface{})
+	client := providerConfig["client"].(*mailformapi.Client)
+
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, logKeyOrderID, id)
+	_, span := startSpan(ctx, spanOrderRead, attrOrderID.String(id))
+	start := time.Now()
+	order, err := client.GetOrder(id)
+	span.SetAttributes(attrOrderState.String(order.Data.State))
+	endSpan(span, err)
+	fields := map[string]any{
+		logKeyLatency:   time.Since(start).Milliseconds(),
+		logKeyRequestID: lastRequestID(m, id),
+	}
+	if isOrderNotFound(err) {
+		tflog.SubsystemWarn(ctx, logSubsystem, "order not found, removing it from state", fields)
+		return nil, err
+	}
+	if err != nil {
+		fields[logKeyError] = err.Error()
+		tflog.SubsystemError(ctx, logSubsystem, "reading order failed", fields)
+		return nil, err
+	}
+	fields[logKeyState] = order.Data.State
+	tflog.SubsystemDebug(ctx, logSubsystem, "read order", fields)
+	return order, nil
+}
+
+// isOrderNotFound returns whether err is the API's response for an order that does not exist
+func isOrderNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "order_not_found")
+}
+
 func flattenLineItems(order *mailform.Order) []any {
 	if order != nil {
 		lineItems := order.Data.Lineitems
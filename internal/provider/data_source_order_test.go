@@ -1,32 +1,55 @@
 package provider
 
 import (
+	"net/http"
 	"regexp"
 	"testing"
 
+	"github.com/circa10a/go-mailform"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccDataSourceOrder(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
 	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccDataSourceOrder,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.mailform_order.foo", "sample_attribute", regexp.MustCompile("^ba")),
+					resource.TestCheckResourceAttrPair("data.mailform_order.example", "id", "mailform_order.example", "id"),
+					resource.TestCheckResourceAttrPair("data.mailform_order.example", "created", "mailform_order.example", "created"),
+					resource.TestCheckResourceAttr("data.mailform_order.example", "state", mailform.StatusQueued),
+					resource.TestCheckResourceAttr("data.mailform_order.example", "lineitems.#", "1"),
+					resource.TestCheckResourceAttr("data.mailform_order.example", "lineitems.0.from_name", "My name"),
 				),
 			},
 		},
 	})
 }
 
-const testAccDataSourceOrder = `
-data "mailform_order" "foo" {
-  sample_attribute = "bar"
+func TestAccDataSourceOrderError(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig:   func() { testAccServer.FailNext(http.StatusInternalServerError, "erroroccurred", "unknown_error") },
+				Config:      testAccDataSourceOrderMissing,
+				ExpectError: regexp.MustCompile("unknown_error"),
+			},
+		},
+	})
+}
+
+const testAccDataSourceOrder = testAccResourceOrder + `
+data "mailform_order" "example" {
+  id = mailform_order.example.id
+}
+`
+
+const testAccDataSourceOrderMissing = `
+data "mailform_order" "example" {
+  id = "ord_missing"
 }
 `
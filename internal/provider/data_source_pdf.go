@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourcePDF renders the same inputs as the mailform_pdf resource in memory,
+// so that nothing depends on a file persisting between plan and apply
+func dataSourcePDF() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for key, attr := range resourcePDF().Schema {
+		// The content is returned rather than written to a file
+		if key == "filename" || key == "base64_content" {
+			continue
+		}
+		s[key] = attr
+	}
+	s["content_base64"] = &schema.Schema{
+		Description: "Base64 encoded content of the PDF. Pass to `pdf_content_base64` of a `mailform_order` to mail it.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+
+	return &schema.Resource{
+		Description: "Render a PDF in memory without writing to the local filesystem.",
+		ReadContext: dataSourcePDFRead,
+		Schema:      s,
+	}
+}
+
+func dataSourcePDFRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	// Orders are replaced when content_base64 changes, so it must be the same on every read
+	if signature := expandPDFDigitalSignature(d); signature != nil {
+		if err := signature.validateReproducible(); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	content, err := pdfResourceContent(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	checksum := sha1.Sum(content)
+	d.SetId(hex.EncodeToString(checksum[:]))
+
+	metadata := newPDFMetadata(content)
+	for key, value := range metadata.values() {
+		if key == "base64_content" {
+			continue
+		}
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("content_base64", metadata.base64); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Trace(ctx, "rendered a pdf data source")
+
+	return nil
+}
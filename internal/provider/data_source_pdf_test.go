@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccDataSourcePDF(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourcePDF,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.mailform_pdf.example", "content_base64", regexp.MustCompile("^JVBERi0"),
+					),
+					resource.TestCheckResourceAttr(
+						"data.mailform_pdf.example", "page_count", "1",
+					),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourcePDF = `
+data "mailform_pdf" "example" {
+	header  = "My Resume"
+	content = "Some resume contents"
+}
+`
+
+func TestRenderPDFContent(t *testing.T) {
+	config := map[string]any{"header": "Header", "content": "Content"}
+
+	d := schema.TestResourceDataRaw(t, dataSourcePDF().Schema, config)
+	if diags := dataSourcePDFRead(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	content, err := base64.StdEncoding.DecodeString(d.Get("content_base64").(string))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "output.pdf")
+	config["filename"] = output
+	if diags := writePDFResource(context.Background(), schema.TestResourceDataRaw(t, resourcePDF().Schema, config)); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	file, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !bytes.Equal(content, file) {
+		t.Error("expected the data source's content to match the file written by the resource")
+	}
+}
+
+func TestDataSourcePDFSignedReproducible(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, time.Now().Add(24*time.Hour))
+	read := func() string {
+		d := schema.TestResourceDataRaw(t, dataSourcePDF().Schema, map[string]any{
+			"header": "Agreement",
+			"digital_signature": []any{map[string]any{
+				"certificate_file": certFile,
+				"private_key_file": keyFile,
+			}},
+		})
+		if diags := dataSourcePDFRead(context.Background(), d, nil); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		return d.Get("content_base64").(string)
+	}
+
+	first := read()
+	time.Sleep(time.Second)
+	if read() != first {
+		t.Error("expected content_base64 of a signed PDF to be the same on every read")
+	}
+}
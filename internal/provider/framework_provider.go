@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ provider.Provider              = &frameworkProvider{}
+	_ provider.ProviderWithFunctions = &frameworkProvider{}
+)
+
+// frameworkProvider is the terraform-plugin-framework half of the provider, which serves the provider
+// functions. It is served alongside the SDKv2 provider returned by New, see NewMuxServer.
+type frameworkProvider struct {
+	version string
+}
+
+// frameworkProviderModel is the provider configuration, identical to the SDKv2 provider schema
+type frameworkProviderModel struct {
+	APIToken     types.String `tfsdk:"api_token"`
+	AuditLogPath types.String `tfsdk:"audit_log_path"`
+	BaseURL      types.String `tfsdk:"base_url"`
+}
+
+// NewFramework returns the terraform-plugin-framework provider
+func NewFramework(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &frameworkProvider{
+			version: version,
+		}
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "mailform"
+	resp.Version = p.version
+}
+
+// Schema must match the SDKv2 provider schema exactly, or the mux server refuses to start.
+// Validation is left to the SDKv2 provider so that errors are not reported twice.
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"api_token": schema.StringAttribute{
+				Optional: true,
+			},
+			"audit_log_path": schema.StringAttribute{
+				MarkdownDescription: auditLogPathDescription,
+				Optional:            true,
+			},
+			"base_url": schema.StringAttribute{
+				MarkdownDescription: baseURLDescription,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// Configure only validates the configuration. The framework provider serves functions alone, which need
+// no client, so none is built until resources or data sources move here. The SDKv2 provider configures the
+// client, its request logger and the audit log.
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config frameworkProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+}
+
+// Resources are the resources migrated from the SDKv2 provider
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{}
+}
+
+// DataSources are the data sources migrated from the SDKv2 provider
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{}
+}
+
+// Functions are provider-defined functions, which need no provider configuration
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newFormatAddressFunction,
+		newValidatePostcodeFunction,
+	}
+}
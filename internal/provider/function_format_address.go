@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/circa10a/terraform-provider-mailform/internal/postal"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var _ function.Function = &formatAddressFunction{}
+
+// addressFields are the attributes of an address without the to_ or from_ prefix used by orders
+var addressFields = []string{"name", "organization", "address_1", "address_2", "city", "state", "postcode", "country"}
+
+// formatAddressFunction formats an address the way Mailform does for the to_formatted and
+// from_formatted attributes of an order
+type formatAddressFunction struct{}
+
+func newFormatAddressFunction() function.Function {
+	return &formatAddressFunction{}
+}
+
+func (f *formatAddressFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_address"
+}
+
+func (f *formatAddressFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Format a postal address",
+		MarkdownDescription: "Formats an address as the `to_formatted` and `from_formatted` attributes of an order do: the name, organization, address lines, `city, state postcode` and country on separate lines, leaving out empty lines.",
+		Parameters: []function.Parameter{
+			function.MapParameter{
+				Name:                "address",
+				MarkdownDescription: fmt.Sprintf("The address, with any of the keys `%s`. These match the address attributes of `mailform_order` without the `to_` or `from_` prefix.", strings.Join(addressFields, "`, `")),
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *formatAddressFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var address map[string]string
+	resp.Error = req.Arguments.Get(ctx, &address)
+	if resp.Error != nil {
+		return
+	}
+
+	keys := maps.Keys(address)
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(addressFields, key) {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("unknown address key %q, must be one of: %s", key, strings.Join(addressFields, ", ")))
+			return
+		}
+	}
+
+	formatted := postal.Format(postal.Address{
+		Name:         address["name"],
+		Organization: address["organization"],
+		Address1:     address["address_1"],
+		Address2:     address["address_2"],
+		City:         address["city"],
+		State:        address["state"],
+		Postcode:     address["postcode"],
+		Country:      address["country"],
+	})
+	resp.Error = resp.Result.Set(ctx, formatted)
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFormatAddressFunction(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  map[string]string
+		expected string
+		err      bool
+	}{
+		{
+			name: "full",
+			address: map[string]string{
+				"name":         "Jane Doe",
+				"organization": "Example Co.",
+				"address_1":    "1 Main St",
+				"address_2":    "Suite 100",
+				"city":         "Seattle",
+				"state":        "WA",
+				"postcode":     "98101",
+				"country":      "US",
+			},
+			expected: "Jane Doe\nExample Co.\n1 Main St\nSuite 100\nSeattle, WA 98101\nUS",
+		},
+		{
+			name:     "optional lines",
+			address:  map[string]string{"name": "Jane Doe", "address_1": "1 Main St", "city": "Seattle", "state": "WA", "postcode": "98101", "country": "US"},
+			expected: "Jane Doe\n1 Main St\nSeattle, WA 98101\nUS",
+		},
+		{
+			name:     "name only",
+			address:  map[string]string{"name": "Jane Doe"},
+			expected: "Jane Doe",
+		},
+		{
+			name:    "unknown key",
+			address: map[string]string{"name": "Jane Doe", "to_city": "Seattle"},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			elements := map[string]attr.Value{}
+			for key, value := range test.address {
+				elements[key] = types.StringValue(value)
+			}
+			result, err := testRunFunction(t, newFormatAddressFunction(), types.MapValueMust(types.StringType, elements))
+			if test.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if actual := result.(types.String).ValueString(); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
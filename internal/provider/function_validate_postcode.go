@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &validatePostcodeFunction{}
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+	// postcodePatterns are the postcode formats of countries commonly mailed to, keyed by ISO 3166-1 alpha-2 code
+	postcodePatterns = map[string]*regexp.Regexp{
+		"AT": regexp.MustCompile(`^\d{4}$`),
+		"AU": regexp.MustCompile(`^\d{4}$`),
+		"BE": regexp.MustCompile(`^\d{4}$`),
+		"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+		"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+		"CH": regexp.MustCompile(`^\d{4}$`),
+		"DE": regexp.MustCompile(`^\d{5}$`),
+		"DK": regexp.MustCompile(`^\d{4}$`),
+		"ES": regexp.MustCompile(`^\d{5}$`),
+		"FR": regexp.MustCompile(`^\d{5}$`),
+		"GB": regexp.MustCompile(`^(GIR ?0AA|[A-Z]{1,2}\d[A-Z\d]? ?\d[ABD-HJLNP-UW-Z]{2})$`),
+		"IE": regexp.MustCompile(`^[AC-FHKNPRTV-Y]\d[\dW] ?[\dAC-FHKNPRTV-Y]{4}$`),
+		"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+		"IT": regexp.MustCompile(`^\d{5}$`),
+		"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+		"MX": regexp.MustCompile(`^\d{5}$`),
+		"NL": regexp.MustCompile(`^[1-9]\d{3} ?[A-Z]{2}$`),
+		"NO": regexp.MustCompile(`^\d{4}$`),
+		"NZ": regexp.MustCompile(`^\d{4}$`),
+		"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+		"PR": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+		"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+		"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	}
+)
+
+// validatePostcodeFunction checks a postcode against the format of its country
+type validatePostcodeFunction struct{}
+
+func newValidatePostcodeFunction() function.Function {
+	return &validatePostcodeFunction{}
+}
+
+func (f *validatePostcodeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_postcode"
+}
+
+func (f *validatePostcodeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check the format of a postcode",
+		MarkdownDescription: "Returns true if a postcode matches the format used by its country. Letters may be in either case. Any non-empty postcode is accepted for countries without a known format, so the result does not mean the postcode exists.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "country",
+				MarkdownDescription: "The ISO 3166-1 alpha-2 code of the country, as used by the `to_country` and `from_country` attributes of an order. Example \"US\"",
+			},
+			function.StringParameter{
+				Name:                "postcode",
+				MarkdownDescription: "The postcode or zip code to check. Example \"00000\"",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *validatePostcodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var country, postcode string
+	resp.Error = req.Arguments.Get(ctx, &country, &postcode)
+	if resp.Error != nil {
+		return
+	}
+
+	if !countryCodePattern.MatchString(country) {
+		resp.Error = function.NewArgumentFuncError(0, "country must be an ISO 3166-1 alpha-2 code, such as \"US\"")
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, validPostcode(country, postcode))
+}
+
+// validPostcode returns whether a postcode matches the format of a country
+func validPostcode(country, postcode string) bool {
+	postcode = strings.ToUpper(strings.TrimSpace(postcode))
+	pattern, ok := postcodePatterns[strings.ToUpper(country)]
+	if !ok {
+		return postcode != ""
+	}
+	return pattern.MatchString(postcode)
+}
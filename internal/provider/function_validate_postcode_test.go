@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidatePostcodeFunction(t *testing.T) {
+	tests := []struct {
+		country  string
+		postcode string
+		expected bool
+		err      bool
+	}{
+		{country: "US", postcode: "98101", expected: true},
+		{country: "us", postcode: "98101-1234", expected: true},
+		{country: "US", postcode: "9810", expected: false},
+		{country: "CA", postcode: "k1a 0b1", expected: true},
+		{country: "CA", postcode: "D1A 0B1", expected: false},
+		{country: "GB", postcode: "SW1A 1AA", expected: true},
+		{country: "GB", postcode: "EC1A1BB", expected: true},
+		{country: "GB", postcode: "12345", expected: false},
+		{country: "NL", postcode: "1012 AB", expected: true},
+		{country: "PL", postcode: "00950", expected: false},
+		{country: "ZW", postcode: "anything", expected: true},
+		{country: "ZW", postcode: " ", expected: false},
+		{country: "USA", postcode: "98101", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.country+" "+test.postcode, func(t *testing.T) {
+			result, err := testRunFunction(t, newValidatePostcodeFunction(), types.StringValue(test.country), types.StringValue(test.postcode))
+			if test.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if actual := result.(types.Bool).ValueBool(); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
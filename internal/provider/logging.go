@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// logSubsystem is the tflog subsystem of Mailform API requests and orders, which masks
+	// the API token and personal details of its entries
+	logSubsystem = "mailform"
+
+	// requestIDHeader identifies a request to the API and in the logs
+	requestIDHeader = "X-Request-Id"
+
+	// Log fields
+	logKeyOrderID   = "order_id"
+	logKeyService   = "service"
+	logKeyMethod    = "http_method"
+	logKeyPath      = "http_path"
+	logKeyStatus    = "http_status"
+	logKeyLatency   = "latency_ms"
+	logKeyRequestID = "request_id"
+	logKeyState     = "state"
+	logKeyError     = "error"
+)
+
+var (
+	orderPathPattern = regexp.MustCompile(`/orders/([^/]+)`)
+
+	// logMaskedFields are the fields holding personal details, should they ever be logged
+	logMaskedFields = []string{
+		"to_name", "to_organization", "to_address_1", "to_address_2", "to_city", "to_postcode",
+		"from_name", "from_organization", "from_address_1", "from_address_2", "from_city", "from_postcode",
+		"bank_account", "check_name", "check_memo", "api_token",
+	}
+)
+
+// newLogContext returns ctx with the mailform subsystem, masking the API token and any other
+// values, such as the addresses of an order, wherever they appear in its logs
+func newLogContext(ctx context.Context, token string, masked ...string) context.Context {
+	secrets := []string{}
+	for _, s := range append([]string{token}, masked...) {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+
+	ctx = tflog.NewSubsystem(ctx, logSubsystem, tflog.WithRootFields())
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, logSubsystem, logMaskedFields...)
+	ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystem, secrets...)
+	ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystem, secrets...)
+	return ctx
+}
+
+// orderLogContext returns the log context of the provider configured with m for an order
+func orderLogContext(ctx context.Context, m any, order mailform.OrderInput) context.Context {
+	providerConfig := m.(map[string]interface{})
+	token, _ := providerConfig["api_token"].(string)
+
+	// Postcodes are only masked by field, as masking digits would also mask order IDs and timestamps
+	ctx = newLogContext(ctx, token,
+		order.ToName, order.ToOrganization, order.ToAddress1, order.ToAddress2, order.ToCity,
+		order.FromName, order.FromOrganization, order.FromAddress1, order.FromAddress2, order.FromCity,
+		order.BankAccount, order.CheckName, order.CheckMemo,
+	)
+	if order.Service != "" {
+		ctx = tflog.SubsystemSetField(ctx, logSubsystem, logKeyService, order.Service)
+	}
+	return ctx
+}
+
+// lastRequestID returns the ID of the latest API request for an order by the provider configured with m
+func lastRequestID(m any, orderID string) string {
+	providerConfig := m.(map[string]interface{})
+	logger, ok := providerConfig["request_logger"].(*requestLogger)
+	if !ok {
+		return ""
+	}
+	return logger.lastRequestID(orderID)
+}
+
+// requestLogger is an http.RoundTripper logging each API request with its method, path, status,
+// latency and request ID.
+type requestLogger struct {
+	// ctx is the log context of the provider configuration, as requests are not made with a context
+	ctx  context.Context
+	next http.RoundTripper
+
+	mu sync.Mutex
+	// requestIDs are the latest request IDs of each order
+	requestIDs map[string]string
+}
+
+func newRequestLogger(ctx context.Context, next http.RoundTripper) *requestLogger {
+	return &requestLogger{
+		ctx:        ctx,
+		next:       next,
+		requestIDs: map[string]string{},
+	}
+}
+
+// RoundTrip sends a request with a new request ID and logs it
+func (l *requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID := newRequestID()
+	req.Header.Set(requestIDHeader, requestID)
+	fields := map[string]any{
+		logKeyMethod:    req.Method,
+		logKeyPath:      req.URL.Path,
+		logKeyRequestID: requestID,
+	}
+
+	start := time.Now()
+	resp, err := l.next.RoundTrip(req)
+	fields[logKeyLatency] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields[logKeyError] = err.Error()
+		tflog.SubsystemError(l.ctx, logSubsystem, "Mailform API request failed", fields)
+		return nil, err
+	}
+
+	// Prefer the API's own ID, which Mailform can look up
+	if id := resp.Header.Get(requestIDHeader); id != "" {
+		requestID = id
+		fields[logKeyRequestID] = id
+	}
+	fields[logKeyStatus] = resp.StatusCode
+
+	orderID, err := responseOrderID(req, resp)
+	if err != nil {
+		return nil, err
+	}
+	if orderID != "" {
+		fields[logKeyOrderID] = orderID
+		l.mu.Lock()
+		l.requestIDs[orderID] = requestID
+		l.mu.Unlock()
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		tflog.SubsystemWarn(l.ctx, logSubsystem, "Mailform API request returned an error", fields)
+	} else {
+		tflog.SubsystemDebug(l.ctx, logSubsystem, "Mailform API request", fields)
+	}
+	return resp, nil
+}
+
+func (l *requestLogger) lastRequestID(orderID string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.requestIDs[orderID]
+}
+
+// responseOrderID returns the order a request is for, reading the ID of created orders from the
+// response. The body is restored so the client can still read it.
+func responseOrderID(req *http.Request, resp *http.Response) (string, error) {
+	if match := orderPathPattern.FindStringSubmatch(req.URL.Path); match != nil {
+		return match[1], nil
+	}
+	if req.Method != http.MethodPost {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var order struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	// Error responses have no order ID
+	_ = json.Unmarshal(body, &order)
+	return order.Data.ID, nil
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
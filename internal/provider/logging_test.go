@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestNewLogContext(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := newLogContext(tflogtest.RootLogger(context.Background(), &logs), "secret-token", "Jane Doe", "")
+
+	tflog.SubsystemDebug(ctx, logSubsystem, "sending secret-token for Jane Doe", map[string]any{
+		"to_address_1": "1 Main St",
+		"detail":       "recipient Jane Doe",
+	})
+
+	for _, secret := range []string{"secret-token", "Jane Doe", "1 Main St"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("expected %q to be masked, got %s", secret, logs.String())
+		}
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := newLogContext(tflogtest.RootLogger(context.Background(), &logs), testAccAPIToken)
+	logger := newRequestLogger(ctx, http.DefaultTransport)
+	client, err := mailformapi.New(&mailformapi.Config{Token: testAccAPIToken, BaseURL: testAccServer.URL, Transport: logger})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	testAccServer.FailNext(http.StatusInternalServerError, "erroroccurred", "unknown_error")
+	if _, err := client.GetOrder("ord_123456"); err == nil {
+		t.Fatal("expected the request to fail")
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %v", entries)
+	}
+	entry := entries[0]
+	if entry["@level"] != "warn" || entry[logKeyMethod] != http.MethodGet || entry[logKeyPath] != "/orders/ord_123456" || entry[logKeyStatus] != float64(http.StatusInternalServerError) {
+		t.Errorf("expected the failed request to be logged, got %v", entry)
+	}
+	if id := logger.lastRequestID("ord_123456"); id == "" || entry[logKeyRequestID] != id {
+		t.Errorf("expected request ID %v to be kept for the order, got %q", entry[logKeyRequestID], id)
+	}
+}
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	addressFontSize   = 10.0
+	addressLineHeight = 4.2
+	addressSpacing    = 6.0
+)
+
+var (
+	// Windows of a standard #10 double window envelope, relative to the top left of a tri-folded page
+	returnAddressWindow    = pdfRect{x: 0.875 * mmPerInch, y: 0.5 * mmPerInch, width: 3.25 * mmPerInch, height: 0.875 * mmPerInch}
+	recipientAddressWindow = pdfRect{x: 0.875 * mmPerInch, y: 2 * mmPerInch, width: 4 * mmPerInch, height: 1.125 * mmPerInch}
+
+	// addressWindowPageSizes are the page sizes that fold into a #10 envelope
+	addressWindowPageSizes = []string{"Letter", "Legal", "A4"}
+
+	errAddressWindowOverlap = errors.New("letterhead overlaps the envelope address windows, increase the top margin or remove the letterhead")
+)
+
+// pdfRect is a rectangular region of a page in millimeters
+type pdfRect struct {
+	x, y, width, height float64
+}
+
+// bottom returns the vertical position of the bottom edge of the region
+func (r pdfRect) bottom() float64 {
+	return r.y + r.height
+}
+
+// intersects reports whether two regions overlap
+func (r pdfRect) intersects(o pdfRect) bool {
+	return r.x < o.x+o.width && o.x < r.x+r.width && r.y < o.bottom() && o.y < r.bottom()
+}
+
+// pdfPlacement is an element printed at a fixed position of a page, such as a barcode
+type pdfPlacement struct {
+	name string
+	page int
+	rect pdfRect
+}
+
+// pdfAddress is a postal address printed within an envelope window
+type pdfAddress struct {
+	name         string
+	organization string
+	address1     string
+	address2     string
+	city         string
+	state        string
+	postcode     string
+	country      string
+}
+
+// lines returns the address formatted for printing. The country is omitted for domestic mail.
+func (a pdfAddress) lines(domesticCountry string) []string {
+	lines := []string{a.name}
+	if a.organization != "" {
+		lines = append(lines, a.organization)
+	}
+	lines = append(lines, a.address1)
+	if a.address2 != "" {
+		lines = append(lines, a.address2)
+	}
+	lines = append(lines, strings.TrimSpace(fmt.Sprintf("%s, %s %s", a.city, a.state, a.postcode)))
+	if a.country != "" && !strings.EqualFold(a.country, domesticCountry) {
+		lines = append(lines, a.country)
+	}
+	return lines
+}
+
+// pdfAddressWindow holds the addresses printed in the windows of an envelope
+type pdfAddressWindow struct {
+	to   pdfAddress
+	from pdfAddress
+}
+
+// expandAddress reads a prefixed address, such as to_name, to_city, from a map of attributes
+func expandAddress(m map[string]any, prefix string) pdfAddress {
+	return pdfAddress{
+		name:         m[prefix+"name"].(string),
+		organization: m[prefix+"organization"].(string),
+		address1:     m[prefix+"address_1"].(string),
+		address2:     m[prefix+"address_2"].(string),
+		city:         m[prefix+"city"].(string),
+		state:        m[prefix+"state"].(string),
+		postcode:     m[prefix+"postcode"].(string),
+		country:      m[prefix+"country"].(string),
+	}
+}
+
+// expandPDFAddressWindow reads the address window configuration of a mailform_pdf resource, if any
+func expandPDFAddressWindow(d resourceGetter) *pdfAddressWindow {
+	windows := d.Get("address_window").([]any)
+	if len(windows) == 0 || windows[0] == nil {
+		return nil
+	}
+
+	m := windows[0].(map[string]any)
+	return &pdfAddressWindow{
+		to:   expandAddress(m, "to_"),
+		from: expandAddress(m, "from_"),
+	}
+}
+
+// text returns all of the text printed in the windows
+func (w *pdfAddressWindow) text() []string {
+	return append(w.from.lines(w.to.country), w.to.lines(w.from.country)...)
+}
+
+// validate ensures the page folds into a window envelope and the addresses fit in their windows
+func (w *pdfAddressWindow) validate(layout pdfLayout) error {
+	if layout.orientation != orientationPortrait {
+		return errors.New("address_window requires a portrait orientation")
+	}
+
+	if !slices.Contains(addressWindowPageSizes, layout.pageSize) {
+		return fmt.Errorf("address_window requires page_size to be one of: %s", strings.Join(addressWindowPageSizes, ", "))
+	}
+
+	if lines := w.from.lines(w.to.country); float64(len(lines))*addressLineHeight > returnAddressWindow.height {
+		return fmt.Errorf("return address has %d lines which do not fit in the envelope window", len(lines))
+	}
+	if lines := w.to.lines(w.from.country); float64(len(lines))*addressLineHeight > recipientAddressWindow.height {
+		return fmt.Errorf("recipient address has %d lines which do not fit in the envelope window", len(lines))
+	}
+
+	return nil
+}
+
+// validatePlacements ensures nothing printed at a fixed position of the first page covers an envelope window
+func (w *pdfAddressWindow) validatePlacements(placements []pdfPlacement) error {
+	windows := []struct {
+		name string
+		rect pdfRect
+	}{
+		{name: "return address", rect: returnAddressWindow},
+		{name: "recipient address", rect: recipientAddressWindow},
+	}
+
+	for _, placement := range placements {
+		if placement.page != 1 {
+			continue
+		}
+		for _, window := range windows {
+			if placement.rect.intersects(window.rect) {
+				return fmt.Errorf("%s on page 1 overlaps the %s envelope window", placement.name, window.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// render prints the addresses in their windows on the current page and moves the
+// cursor beneath them so that no body content overlaps the windows.
+func (w *pdfAddressWindow) render(pdf *gofpdf.Fpdf, font pdfFont, tr func(string) string) error {
+	// Anything printed on the page so far, such as the letterhead, must end above the windows
+	left, top, _, _ := pdf.GetMargins()
+	if pdf.GetY() > top && pdf.GetY() > returnAddressWindow.y {
+		return errAddressWindowOverlap
+	}
+
+	pdf.SetFont(font.family, font.style(""), addressFontSize)
+	windows := []struct {
+		rect  pdfRect
+		lines []string
+	}{
+		{rect: returnAddressWindow, lines: w.from.lines(w.to.country)},
+		{rect: recipientAddressWindow, lines: w.to.lines(w.from.country)},
+	}
+
+	for _, window := range windows {
+		for i, line := range window.lines {
+			line = tr(line)
+			if pdf.GetStringWidth(line) > window.rect.width {
+				return fmt.Errorf("address line %q is too wide for the envelope window", window.lines[i])
+			}
+			pdf.SetXY(window.rect.x, window.rect.y+float64(i)*addressLineHeight)
+			pdf.CellFormat(window.rect.width, addressLineHeight, line, "", 0, "L", false, 0, "")
+		}
+	}
+
+	pdf.SetXY(left, recipientAddressWindow.bottom()+addressSpacing)
+
+	return nil
+}
+
+// addressWindowSchema reuses the to_* and from_* inputs of a mailform_order.
+// Unlike an order, a PDF is updated in place when an address changes.
+func addressWindowSchema() map[string]*schema.Schema {
+	addressSchema := map[string]*schema.Schema{}
+	for key, s := range orderInputSchema {
+		if strings.HasPrefix(key, "to_") || strings.HasPrefix(key, "from_") {
+			c := *s
+			c.ForceNew = false
+			addressSchema[key] = &c
+		}
+	}
+	return addressSchema
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"testing"
+)
+
+func testPDFAddressWindow() *pdfAddressWindow {
+	return &pdfAddressWindow{
+		to: pdfAddress{
+			name:     "A name",
+			address1: "Address 1",
+			city:     "Seattle",
+			state:    "WA",
+			postcode: "00000",
+			country:  "US",
+		},
+		from: pdfAddress{
+			name:         "My name",
+			organization: "My company",
+			address1:     "My Address 1",
+			city:         "Dallas",
+			state:        "TX",
+			postcode:     "00000",
+			country:      "US",
+		},
+	}
+}
+
+func TestPDFAddressLines(t *testing.T) {
+	address := testPDFAddressWindow().to
+	if lines := address.lines("US"); len(lines) != 3 {
+		t.Errorf("expected domestic address to omit country, got %q", lines)
+	}
+	if lines := address.lines("CA"); len(lines) != 4 || lines[3] != "US" {
+		t.Errorf("expected international address to include country, got %q", lines)
+	}
+}
+
+func TestPDFAddressWindowRender(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	font := pdfFont{family: defaultFontFamily}
+	window := testPDFAddressWindow()
+
+	if err := window.validate(layout); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	pdf := layout.newDocument(defaultMargin)
+	tr := font.register(pdf)
+	pdf.AddPage()
+	if err := window.render(pdf, font, tr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if pdf.GetY() <= recipientAddressWindow.bottom() {
+		t.Errorf("expected body to start beneath the recipient window, got y=%v", pdf.GetY())
+	}
+
+	_, err := renderPDFContent(pdfOptions{header: "Title", content: "Body", font: font, layout: layout, addressWindow: window})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestPDFAddressWindowOverlap(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	font := pdfFont{family: defaultFontFamily}
+	opts := pdfOptions{
+		header:        "Title",
+		font:          font,
+		layout:        layout,
+		letterhead:    pdfLetterhead{image: writeTestImage(t, 400, 100)},
+		addressWindow: testPDFAddressWindow(),
+	}
+
+	if _, err := renderPDFContent(opts); err != errAddressWindowOverlap {
+		t.Errorf("expected %s, got %v", errAddressWindowOverlap, err)
+	}
+
+	opts.layout = testPDFLayout("Postcard4x6", orientationLandscape)
+	opts.letterhead = pdfLetterhead{}
+	if err := opts.validate(); err == nil {
+		t.Error("expected postcard with address window to be invalid")
+	}
+}
+
+func TestPDFAddressWindowPlacements(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	font := pdfFont{family: defaultFontFamily}
+	signature := writeTestImage(t, 300, 100)
+
+	cases := []struct {
+		name     string
+		opts     pdfOptions
+		expected string
+	}{
+		{
+			name:     "qr code over recipient window",
+			opts:     pdfOptions{barcodes: []pdfBarcode{{symbology: symbologyQR, payload: "payload", page: 1, rect: pdfRect{x: 80, y: 60, width: 30, height: 30}}}},
+			expected: `QR "payload" on page 1 overlaps the recipient address envelope window`,
+		},
+		{
+			name:     "barcode over return window",
+			opts:     pdfOptions{barcodes: []pdfBarcode{{symbology: symbologyCode128, payload: "payload", page: 1, rect: pdfRect{x: 90, y: 20, width: 40, height: 10}}}},
+			expected: `Code128 "payload" on page 1 overlaps the return address envelope window`,
+		},
+		{
+			name:     "signature over return window",
+			opts:     pdfOptions{signature: &pdfSignatureStamp{image: signature, page: 1, rect: pdfRect{x: 30, y: 20, width: 60}, name: "Jane Doe", date: "2023-03-01"}},
+			expected: "signature on page 1 overlaps the return address envelope window",
+		},
+		{
+			name:     "letterhead image",
+			opts:     pdfOptions{letterhead: pdfLetterhead{image: writeTestImage(t, 400, 100)}},
+			expected: errAddressWindowOverlap.Error(),
+		},
+		{
+			name: "elements clear of the windows",
+			opts: pdfOptions{
+				barcodes:  []pdfBarcode{{symbology: symbologyQR, payload: "payload", page: 1, rect: pdfRect{x: 150, y: 20, width: 30, height: 30}}},
+				signature: &pdfSignatureStamp{image: signature, page: 1, rect: pdfRect{x: 20, y: 200, width: 60}, name: "Jane Doe"},
+			},
+		},
+		{
+			name: "elements on later pages",
+			opts: pdfOptions{
+				barcodes:  []pdfBarcode{{symbology: symbologyCode128, payload: "payload", page: 2, rect: pdfRect{x: 30, y: 60, width: 40, height: 10}}},
+				signature: &pdfSignatureStamp{image: signature, page: 2, rect: pdfRect{x: 30, y: 20, width: 60}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := c.opts
+			opts.header = "Title"
+			opts.font = font
+			opts.layout = layout
+			opts.addressWindow = testPDFAddressWindow()
+
+			err := opts.validate()
+			if c.expected == "" {
+				if err != nil {
+					t.Errorf("err: %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("expected %q, got %v", c.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/qr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	symbologyQR         = "QR"
+	symbologyCode128    = "Code128"
+	symbologyDataMatrix = "DataMatrix"
+)
+
+var (
+	symbologies = []string{symbologyQR, symbologyCode128, symbologyDataMatrix}
+)
+
+// pdfBarcode is a barcode or QR code drawn on a page of a PDF
+type pdfBarcode struct {
+	symbology string
+	payload   string
+	page      int
+	rect      pdfRect
+}
+
+// expandPDFBarcodes reads the qr_code and barcode blocks of a mailform_pdf resource
+func expandPDFBarcodes(d resourceGetter) []pdfBarcode {
+	codes := []pdfBarcode{}
+	for _, c := range d.Get("qr_code").([]any) {
+		m := c.(map[string]any)
+		size := m["size"].(float64)
+		codes = append(codes, pdfBarcode{
+			symbology: symbologyQR,
+			payload:   m["payload"].(string),
+			page:      m["page"].(int),
+			rect:      pdfRect{x: m["x"].(float64), y: m["y"].(float64), width: size, height: size},
+		})
+	}
+	for _, c := range d.Get("barcode").([]any) {
+		m := c.(map[string]any)
+		codes = append(codes, pdfBarcode{
+			symbology: m["symbology"].(string),
+			payload:   m["payload"].(string),
+			page:      m["page"].(int),
+			rect:      pdfRect{x: m["x"].(float64), y: m["y"].(float64), width: m["width"].(float64), height: m["height"].(float64)},
+		})
+	}
+	return codes
+}
+
+// encode generates the barcode's modules, one pixel per module
+func (b pdfBarcode) encode() (barcode.Barcode, error) {
+	var code barcode.Barcode
+	var err error
+
+	switch b.symbology {
+	case symbologyQR:
+		code, err = qr.Encode(b.payload, qr.M, qr.Auto)
+	case symbologyCode128:
+		code, err = code128.Encode(b.payload)
+	case symbologyDataMatrix:
+		code, err = datamatrix.Encode(b.payload)
+	default:
+		err = fmt.Errorf("unsupported symbology %q", b.symbology)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode %s payload %q: %w", b.symbology, b.payload, err)
+	}
+
+	return code, nil
+}
+
+// validate ensures the payload can be encoded and the code lies within the page
+func (b pdfBarcode) validate(layout pdfLayout) error {
+	if _, err := b.encode(); err != nil {
+		return err
+	}
+
+	width, height := layout.pageDimensions()
+	if b.rect.x+b.rect.width > width || b.rect.bottom() > height {
+		return fmt.Errorf("%s %q extends beyond the %.1fx%.1fmm page", b.symbology, b.payload, width, height)
+	}
+
+	return nil
+}
+
+// render draws the code on its page as vector rectangles so that it stays sharp at any print resolution
+func (b pdfBarcode) render(pdf *gofpdf.Fpdf) error {
+	code, err := b.encode()
+	if err != nil {
+		return err
+	}
+
+	lastPage := pdf.PageNo()
+	if b.page > lastPage {
+		return fmt.Errorf("%s %q is placed on page %d but the PDF only has %d pages", b.symbology, b.payload, b.page, lastPage)
+	}
+	pdf.SetPage(b.page)
+	defer pdf.SetPage(lastPage)
+
+	bounds := code.Bounds()
+	columns, rows := bounds.Dx(), bounds.Dy()
+	moduleWidth := b.rect.width / float64(columns)
+	moduleHeight := b.rect.height / float64(rows)
+
+	pdf.SetFillColor(0, 0, 0)
+	for y := 0; y < rows; y++ {
+		// Adjacent dark modules are merged into a single bar
+		for x := 0; x < columns; {
+			if !isDark(code.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+				x++
+				continue
+			}
+			start := x
+			for x < columns && isDark(code.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+				x++
+			}
+			pdf.Rect(b.rect.x+float64(start)*moduleWidth, b.rect.y+float64(y)*moduleHeight, float64(x-start)*moduleWidth, moduleHeight, "F")
+		}
+	}
+
+	return nil
+}
+
+// isDark reports whether a barcode module should be printed
+func isDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 128
+}
+
+// barcodePositionSchema returns the inputs shared by qr_code and barcode blocks
+func barcodePositionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"payload": {
+			Description: "The data to encode.",
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+		"x": {
+			Description:  "Distance in millimeters from the left edge of the page to the code. Leave a clear margin around the code so it can be scanned.",
+			Type:         schema.TypeFloat,
+			Required:     true,
+			ValidateFunc: validation.FloatAtLeast(0),
+		},
+		"y": {
+			Description:  "Distance in millimeters from the top edge of the page to the code.",
+			Type:         schema.TypeFloat,
+			Required:     true,
+			ValidateFunc: validation.FloatAtLeast(0),
+		},
+		"page": {
+			Description:  "The page to draw the code on. Defaults to `1`.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+	}
+}
+
+// qrCodeSchema describes a QR code drawn on a mailform_pdf resource
+func qrCodeSchema() map[string]*schema.Schema {
+	s := barcodePositionSchema()
+	s["size"] = &schema.Schema{
+		Description:  "Width and height of the QR code in millimeters.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	}
+	return s
+}
+
+// barcodeSchema describes a barcode drawn on a mailform_pdf resource
+func barcodeSchema() map[string]*schema.Schema {
+	s := barcodePositionSchema()
+	s["symbology"] = &schema.Schema{
+		Description:  fmt.Sprintf("The type of barcode. Must be one of: `%s`. Defaults to `%s`.", strings.Join(symbologies, "`, `"), symbologyCode128),
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      symbologyCode128,
+		ValidateFunc: validation.StringInSlice(symbologies, false),
+	}
+	s["width"] = &schema.Schema{
+		Description:  "Width of the barcode in millimeters.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	}
+	s["height"] = &schema.Schema{
+		Description:  "Height of the barcode in millimeters.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPDFBarcodeRender(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	codes := []pdfBarcode{
+		{symbology: symbologyQR, payload: "https://example.com/respond", page: 1, rect: pdfRect{x: 160, y: 230, width: 30, height: 30}},
+		{symbology: symbologyCode128, payload: "TRACK-0001", page: 1, rect: pdfRect{x: 20, y: 240, width: 60, height: 15}},
+		{symbology: symbologyDataMatrix, payload: "TRACK-0001", page: 1, rect: pdfRect{x: 100, y: 240, width: 15, height: 15}},
+	}
+
+	for _, code := range codes {
+		if err := code.validate(layout); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		pdf := layout.newDocument(defaultMargin)
+		pdf.SetCompression(false)
+		pdf.AddPage()
+		if err := code.render(pdf); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte(" re f")) {
+			t.Errorf("expected %s to be drawn as filled rectangles", code.symbology)
+		}
+	}
+
+	opts := pdfOptions{header: "Title", font: pdfFont{family: defaultFontFamily}, layout: layout, barcodes: codes}
+	if _, err := renderPDFContent(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestPDFBarcodeValidate(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+
+	outside := pdfBarcode{symbology: symbologyQR, payload: "payload", page: 1, rect: pdfRect{x: 200, y: 10, width: 30, height: 30}}
+	if err := outside.validate(layout); err == nil {
+		t.Error("expected code outside of the page to be invalid")
+	}
+
+	unencodable := pdfBarcode{symbology: symbologyCode128, payload: "漢字", page: 1, rect: pdfRect{x: 10, y: 10, width: 30, height: 10}}
+	if err := unencodable.validate(layout); err == nil {
+		t.Error("expected payload that cannot be encoded to be invalid")
+	}
+
+	missingPage := pdfBarcode{symbology: symbologyQR, payload: "payload", page: 3, rect: pdfRect{x: 10, y: 10, width: 30, height: 30}}
+	pdf := layout.newDocument(defaultMargin)
+	pdf.AddPage()
+	if err := missingPage.render(pdf); err == nil {
+		t.Error("expected code on a page that does not exist to fail")
+	}
+}
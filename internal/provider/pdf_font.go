@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultFontFamily = "Arial"
+)
+
+var (
+	// coreFontFamilies are the fonts every PDF reader provides, which gofpdf renders in cp1252
+	coreFontFamilies = []string{"Arial", "Courier", "Helvetica", "Times"}
+)
+
+// newCP1252Translator returns a translator of text for the core fonts, which replaces runes outside of cp1252
+// with ".". Translators reuse a buffer between calls, so each must only be used by one goroutine.
+func newCP1252Translator() func(string) string {
+	return gofpdf.New("P", "mm", "A4", "").UnicodeTranslatorFromDescriptor("")
+}
+
+// pdfFont describes the font family used to render text in a PDF.
+// If file is empty, family refers to one of the core PDF fonts.
+type pdfFont struct {
+	family     string
+	file       string
+	bold       string
+	italic     string
+	boldItalic string
+}
+
+// resourceGetter is satisfied by both *schema.ResourceData and *schema.ResourceDiff
+type resourceGetter interface {
+	Get(key string) any
+}
+
+// expandPDFFont reads the font configuration of a mailform_pdf resource
+func expandPDFFont(d resourceGetter) pdfFont {
+	font := pdfFont{
+		family: d.Get("font_family").(string),
+		file:   d.Get("font_file").(string),
+	}
+
+	if fonts := d.Get("fonts").([]any); len(fonts) > 0 && fonts[0] != nil {
+		variants := fonts[0].(map[string]any)
+		font.bold = variants["bold"].(string)
+		font.italic = variants["italic"].(string)
+		font.boldItalic = variants["bold_italic"].(string)
+	}
+
+	if font.family == "" {
+		font.family = defaultFontFamily
+		if font.file != "" {
+			font.family = strings.TrimSuffix(filepath.Base(font.file), filepath.Ext(font.file))
+		}
+	}
+
+	return font
+}
+
+// variants returns the font files keyed by the gofpdf style string
+func (f pdfFont) variants() map[string]string {
+	variants := map[string]string{}
+	if f.file == "" {
+		return variants
+	}
+	variants[""] = f.file
+	if f.bold != "" {
+		variants["B"] = f.bold
+	}
+	if f.italic != "" {
+		variants["I"] = f.italic
+	}
+	if f.boldItalic != "" {
+		variants["BI"] = f.boldItalic
+	}
+	return variants
+}
+
+// register adds the font to the document and returns a function translating
+// UTF-8 text into something the font can render.
+func (f pdfFont) register(pdf *gofpdf.Fpdf) func(string) string {
+	if f.file == "" {
+		// Core fonts are limited to cp1252 so text needs to be translated
+		return pdf.UnicodeTranslatorFromDescriptor("")
+	}
+
+	// Fonts are registered in a stable order to keep output reproducible
+	variants := f.variants()
+	styles := maps.Keys(variants)
+	slices.Sort(styles)
+	for _, style := range styles {
+		pdf.AddUTF8Font(f.family, style, variants[style])
+	}
+
+	return func(s string) string { return s }
+}
+
+// style returns the requested style if the font supports it, falling back to regular.
+func (f pdfFont) style(style string) string {
+	if f.file == "" {
+		return style
+	}
+	if _, ok := f.variants()[style]; ok {
+		return style
+	}
+	return ""
+}
+
+// validate ensures a font without a font file is one of the core fonts. PDFs of images have no font.
+func (f pdfFont) validate() error {
+	if f.family == "" || f.file != "" || slices.ContainsFunc(coreFontFamilies, func(family string) bool { return strings.EqualFold(family, f.family) }) {
+		return nil
+	}
+	return fmt.Errorf("font_family %q is not a core font (%s), set font_file to embed it", f.family, strings.Join(coreFontFamilies, ", "))
+}
+
+// supports returns a function reporting whether the font for the given style can render a rune
+func (f pdfFont) supports(style string) (func(rune) bool, error) {
+	file, ok := f.variants()[f.style(style)]
+	if !ok {
+		tr := newCP1252Translator()
+		return func(r rune) bool { return r == '.' || tr(string(r)) != "." }, nil
+	}
+
+	ttf, err := gofpdf.TtfParse(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse font %s: %w", file, err)
+	}
+	return func(r rune) bool {
+		// gofpdf only supports runes in the basic multilingual plane
+		if r > 0xFFFF {
+			return false
+		}
+		_, ok := ttf.Chars[uint16(r)]
+		return ok
+	}, nil
+}
+
+// unsupportedRunes returns every rune in text that the font for the given style cannot render
+func (f pdfFont) unsupportedRunes(style string, text ...string) ([]rune, error) {
+	supported, err := f.supports(style)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[rune]bool{}
+	unsupported := []rune{}
+	for _, s := range text {
+		for _, r := range s {
+			if seen[r] || unicode.IsControl(r) {
+				continue
+			}
+			seen[r] = true
+			if !supported(r) {
+				unsupported = append(unsupported, r)
+			}
+		}
+	}
+
+	return unsupported, nil
+}
+
+// validateRunes ensures every character of text can be rendered using the font for the given style
+func (f pdfFont) validateRunes(style string, text ...string) error {
+	unsupported, err := f.unsupportedRunes(style, text...)
+	if err != nil {
+		return err
+	}
+
+	if len(unsupported) > 0 {
+		quoted := make([]string, len(unsupported))
+		for i, r := range unsupported {
+			quoted[i] = fmt.Sprintf("%q (%U)", r, r)
+		}
+		if f.file == "" {
+			return fmt.Errorf("core font %s is limited to cp1252 and cannot render characters: %s. Set font_file to a TrueType font that includes them", f.family, strings.Join(quoted, ", "))
+		}
+		return fmt.Errorf("font %s cannot render characters: %s", f.variants()[f.style(style)], strings.Join(quoted, ", "))
+	}
+
+	return nil
+}
+
+// splitText wraps text to fit within width using the document's current font.
+// Lines are returned ready to be written, so core font text is translated.
+func (f pdfFont) splitText(pdf *gofpdf.Fpdf, tr func(string) string, text string, width float64) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	if f.file != "" {
+		return pdf.SplitText(text, width)
+	}
+
+	lines := []string{}
+	for _, line := range pdf.SplitLines([]byte(tr(text)), width) {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// validateFontFile ensures a font file exists and is a TrueType font gofpdf can embed
+func validateFontFile(val any, key string) (warns []string, errs []error) {
+	fontFilename := val.(string)
+	if fontFilename == "" {
+		return warns, errs
+	}
+
+	if _, err := gofpdf.TtfParse(fontFilename); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %s is not a valid TrueType font: %w", key, fontFilename, err))
+	}
+
+	return warns, errs
+}
+
+// fontsSchema describes the optional style variants of font_file
+var fontsSchema = map[string]*schema.Schema{
+	"bold": {
+		Description:  "The TrueType font file used for bold text, such as the header.",
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateFontFile,
+	},
+	"italic": {
+		Description:  "The TrueType font file used for italic text.",
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateFontFile,
+	},
+	"bold_italic": {
+		Description:  "The TrueType font file used for bold italic text.",
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateFontFile,
+	},
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testFontFile = "./testdata/calligra.ttf"
+
+func TestRenderPDFUTF8Font(t *testing.T) {
+	font := pdfFont{family: "calligra", file: testFontFile}
+	opts := pdfOptions{
+		header:  "Café",
+		content: "Crème brûlée",
+		font:    font,
+		layout:  testPDFLayout(defaultPageSize, orientationPortrait),
+	}
+
+	content, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if newPDFMetadata(content).pageCount != 1 {
+		t.Error("expected a single page PDF to be rendered")
+	}
+}
+
+func TestPDFFontValidateRunes(t *testing.T) {
+	font := pdfFont{family: "calligra", file: testFontFile}
+
+	err := font.validateRunes("", "Total: 10 €, 漢字")
+	if err == nil {
+		t.Fatal("expected unsupported characters to be reported")
+	}
+
+	for _, expected := range []string{"U+20AC", "U+6F22", "U+5B57"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q to report %s", err, expected)
+		}
+	}
+
+	// Core fonts render cp1252, which includes €
+	core := pdfFont{family: defaultFontFamily}
+	if err := core.validateRunes("", "10 €. Crème brûlée"); err != nil {
+		t.Errorf("unexpected err: %s", err)
+	}
+	err = core.validateRunes("", "Привет, 漢字")
+	if err == nil {
+		t.Fatal("expected characters outside of cp1252 to be reported for core fonts")
+	}
+	for _, expected := range []string{"U+041F", "U+6F22", "font_file"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q to report %s", err, expected)
+		}
+	}
+}
+
+// TestPDFFontValidateRunesParallel validates text from concurrent goroutines, as Terraform does for
+// resources it creates in parallel. Run with -race to detect shared translator state.
+func TestPDFFontValidateRunesParallel(t *testing.T) {
+	core := pdfFont{family: defaultFontFamily}
+	texts := []string{"10 €. Crème brûlée", "Привет, 漢字"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(text string, valid bool) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := core.validateRunes("", text); (err == nil) != valid {
+					t.Errorf("expected %q to be valid %t, got %v", text, valid, err)
+					return
+				}
+			}
+		}(texts[i%2], i%2 == 0)
+	}
+	wg.Wait()
+}
+
+func TestPDFFontValidate(t *testing.T) {
+	for _, family := range []string{"Arial", "courier", "Helvetica", "Times"} {
+		if err := (pdfFont{family: family}).validate(); err != nil {
+			t.Errorf("unexpected err for %s: %s", family, err)
+		}
+	}
+	if err := (pdfFont{family: "Calligrapher"}).validate(); err == nil {
+		t.Error("expected a family that is not a core font to require font_file")
+	}
+	if err := (pdfFont{family: "Calligrapher", file: testFontFile}).validate(); err != nil {
+		t.Errorf("unexpected err: %s", err)
+	}
+}
@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+var (
+	fontResourcesPattern = regexp.MustCompile(`(?s)/Font\s*<<(.*?)>>`)
+	fontResourcePattern  = regexp.MustCompile(`/(\w+)\s+(\d+)\s+\d+\s+R`)
+	setFontPattern       = regexp.MustCompile(`/(\w+)\s+([\d.]+)\s+Tf`)
+	showTextPattern      = regexp.MustCompile(`(?s)BT\s+(-?[\d.]+)\s+(-?[\d.]+)\s+Td\s+\(((?:\\.|[^\\)])*)\)\s*Tj`)
+	drawImagePattern     = regexp.MustCompile(`(-?[\d.]+) (-?[\d.]+) (-?[\d.]+) (-?[\d.]+) (-?[\d.]+) (-?[\d.]+) cm /(\w+) Do`)
+	rectanglePattern     = regexp.MustCompile(`\bre\b`)
+	linePattern          = regexp.MustCompile(`\bl\s+S\b`)
+	operatorPattern      = regexp.MustCompile(`/\w+\s+[\d.]+\s+Tf|BT\s+-?[\d.]+\s+-?[\d.]+\s+Td\s+\((?:\\.|[^\\)])*\)\s*Tj|(?:-?[\d.]+ ){6}cm /\w+ Do`)
+)
+
+// TestPDFGolden renders every mode of a mailform_pdf and compares the text, images and page geometry
+// of the result with testdata/golden. Run with -update to accept intended layout changes.
+func TestPDFGolden(t *testing.T) {
+	font := filepath.Join("testdata", "calligra.ttf")
+	address := map[string]any{
+		"to_name":        "Jane Doe",
+		"to_address_1":   "1 Main St",
+		"to_city":        "Seattle",
+		"to_state":       "WA",
+		"to_postcode":    "98101",
+		"to_country":     "US",
+		"from_name":      "John Doe",
+		"from_address_1": "2 Main St",
+		"from_city":      "Dallas",
+		"from_state":     "TX",
+		"from_postcode":  "75001",
+		"from_country":   "US",
+	}
+
+	tests := []struct {
+		name   string
+		config func(t *testing.T) map[string]any
+	}{
+		{
+			name: "text",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{"header": "My Resume", "content": "Some resume contents"}
+			},
+		},
+		{
+			name: "text_layout",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"header":      "Landscape",
+					"content":     strings.Repeat("Wrapped content on a landscape A4 page. ", 12),
+					"page_size":   "A4",
+					"orientation": orientationLandscape,
+					"margins":     []any{map[string]any{"top": 20.0, "right": 15.0, "bottom": 20.0, "left": 25.0}},
+					"font_size":   14.0,
+					"line_height": 10.0,
+				}
+			},
+		},
+		{
+			name: "text_custom_size",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{"header": "Custom", "content": "Custom page", "page_size": pageSizeCustom, "page_width": 100.0, "page_height": 150.0}
+			},
+		},
+		{
+			name: "text_letterhead",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"header":           "Newsletter",
+					"content":          strings.Repeat("A line of the newsletter\n", 40),
+					"letterhead_image": writeTestImage(t, 400, 50),
+					"header_text":      "Example Co.",
+					"footer_text":      "Example Co. is not a real company",
+					"page_numbers":     true,
+				}
+			},
+		},
+		{
+			name: "text_font",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{"header": "Überschrift", "content": "Grüße aus Köln", "font_file": font}
+			},
+		},
+		{
+			name: "text_address_window",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{"header": "Invoice", "content": "Amount due: $42", "address_window": []any{address}}
+			},
+		},
+		{
+			name: "text_table",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"header":  "Invoice",
+					"content": "Items",
+					"table": []any{map[string]any{
+						"column": []any{
+							map[string]any{"header": "Item"},
+							map[string]any{"header": "Amount", "width": 30.0, "alignment": "right"},
+						},
+						"rows":   []any{[]any{"Widget", "$40"}, []any{"Shipping", "$2"}},
+						"totals": []any{"Total", "$42"},
+						"zebra":  true,
+					}},
+				}
+			},
+		},
+		{
+			name: "text_barcodes",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"header":  "Return label",
+					"content": "Scan below",
+					"qr_code": []any{map[string]any{"payload": "https://example.com", "x": 20.0, "y": 60.0, "size": 30.0, "page": 1}},
+					"barcode": []any{map[string]any{"payload": "1Z999AA10123456784", "x": 20.0, "y": 100.0, "width": 80.0, "height": 15.0, "page": 1, "symbology": "Code128"}},
+				}
+			},
+		},
+		{
+			name: "text_signature",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"header":    "Agreement",
+					"content":   "Signed below",
+					"signature": []any{map[string]any{"image": writeTestImage(t, 300, 100), "x": 20.0, "y": 200.0, "width": 60.0, "page": 1, "name": "Jane Doe", "date": "2023-03-01"}},
+				}
+			},
+		},
+		{
+			name: "image",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{"image_filename": writeTestImage(t, 600, 400), "page_size": "Postcard4x6", "orientation": orientationLandscape}
+			},
+		},
+		{
+			name: "image_fit",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"image_filename": writeTestImage(t, 600, 400),
+					"image_fit":      "contain",
+					"margins":        []any{map[string]any{"top": 10.0, "right": 10.0, "bottom": 10.0, "left": 10.0}},
+				}
+			},
+		},
+		{
+			name: "images",
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"images":      []any{writeTestImage(t, 600, 400), writeTestImage(t, 400, 600)},
+					"image_fit":   "cover",
+					"auto_rotate": true,
+					"page_size":   "Postcard6x9",
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Rendered as the resource does, writing the file in place
+			output := filepath.Join(t.TempDir(), "output.pdf")
+			config := test.config(t)
+			config["filename"] = output
+			d := schema.TestResourceDataRaw(t, resourcePDF().Schema, config)
+			if diags := writePDFResource(context.Background(), d); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+			content, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			actual := summarizePDF(t, content)
+			golden := filepath.Join("testdata", "golden", test.name+".txt")
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(actual), 0o644); err != nil {
+					t.Fatalf("err: %s", err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("err: %s, run the test with -update to create the golden file", err)
+			}
+			if actual != string(expected) {
+				t.Errorf("rendered PDF does not match %s, run the test with -update if the change is intended\n--- expected\n%s\n--- actual\n%s", golden, expected, actual)
+			}
+		})
+	}
+}
+
+// summarizePDF describes the page geometry, text and images of each page. Positions are in
+// millimeters from the top left corner of the page, as mailform_pdf inputs are.
+func summarizePDF(t *testing.T, content []byte) string {
+	t.Helper()
+
+	objects := map[int][]byte{}
+	for _, object := range pdfObjects(content) {
+		objects[object.number] = object.body
+	}
+
+	// Composite fonts, such as embedded TrueType fonts, encode text as UTF-16
+	composite := map[string]bool{}
+	for _, body := range objects {
+		for _, resources := range fontResourcesPattern.FindAllSubmatch(streamDictionary(body), -1) {
+			for _, ref := range fontResourcePattern.FindAllSubmatch(resources[1], -1) {
+				number, _ := strconv.Atoi(string(ref[2]))
+				composite[string(ref[1])] = bytes.Contains(objects[number], []byte("/Type0"))
+			}
+		}
+	}
+
+	var summary strings.Builder
+	pages := pdfPages(content)
+	fmt.Fprintf(&summary, "pages: %d\n", len(pages))
+	for i, page := range pages {
+		box := pageMediaBox(content, page.body)
+		if box == nil {
+			t.Fatalf("page %d has no media box", i+1)
+		}
+		height := box[3] - box[1]
+		fmt.Fprintf(&summary, "\npage %d: %.1f x %.1f mm\n", i+1, pointsToMillimeters(box[2]-box[0]), pointsToMillimeters(height))
+
+		stream := []byte{}
+		if match := contentsPattern.FindSubmatch(page.body); match != nil {
+			for _, ref := range referencePattern.FindAllSubmatch(match[1], -1) {
+				number, _ := strconv.Atoi(string(ref[1]))
+				data, ok := streamData(objects[number])
+				if !ok {
+					t.Fatalf("page %d content stream %d cannot be read", i+1, number)
+				}
+				stream = append(stream, data...)
+			}
+		}
+
+		font, size := "", ""
+		for _, operation := range operatorPattern.FindAll(stream, -1) {
+			if match := setFontPattern.FindSubmatch(operation); match != nil {
+				font, size = string(match[1]), string(match[2])
+			} else if match := showTextPattern.FindSubmatch(operation); match != nil {
+				x, _ := strconv.ParseFloat(string(match[1]), 64)
+				y, _ := strconv.ParseFloat(string(match[2]), 64)
+				text := unescapePDFString(match[3])
+				if composite[font] {
+					text = decodeUTF16(text)
+				}
+				fmt.Fprintf(&summary, "text %.1f,%.1f %spt: %s\n", pointsToMillimeters(x), pointsToMillimeters(height-y), strings.TrimRight(strings.TrimRight(size, "0"), "."), strconv.Quote(text))
+			} else if match := drawImagePattern.FindSubmatch(operation); match != nil {
+				m := make([]float64, 6)
+				for j := range m {
+					m[j], _ = strconv.ParseFloat(string(match[j+1]), 64)
+				}
+				w, h := math.Hypot(m[0], m[1]), math.Hypot(m[2], m[3])
+				fmt.Fprintf(&summary, "image %.1f,%.1f %.1fx%.1f mm\n", pointsToMillimeters(m[4]), pointsToMillimeters(height-m[5]-h), pointsToMillimeters(w), pointsToMillimeters(h))
+			}
+		}
+		fmt.Fprintf(&summary, "rectangles: %d\n", len(rectanglePattern.FindAll(stream, -1)))
+		fmt.Fprintf(&summary, "lines: %d\n", len(linePattern.FindAll(stream, -1)))
+	}
+
+	return summary.String()
+}
+
+// unescapePDFString decodes the escape sequences of a PDF literal string
+func unescapePDFString(s []byte) string {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := i + 1
+			for end < len(s) && end < i+3 && s[end] >= '0' && s[end] <= '7' {
+				end++
+			}
+			value, _ := strconv.ParseUint(string(s[i:end]), 8, 8)
+			out = append(out, byte(value))
+			i = end - 1
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
+
+func decodeUTF16(s string) string {
+	units := make([]uint16, len(s)/2)
+	for i := range units {
+		units[i] = uint16(s[2*i])<<8 | uint16(s[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"  // Register GIF decoder for transcoding
+	_ "image/jpeg" // Register JPEG decoder for rotation
+	"image/png"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/exp/slices"
+	_ "golang.org/x/image/bmp"  // Register BMP decoder for transcoding
+	_ "golang.org/x/image/tiff" // Register TIFF decoder for transcoding
+	_ "golang.org/x/image/webp" // Register WebP decoder for transcoding
+)
+
+const (
+	imageFitContain = "contain"
+	imageFitCover   = "cover"
+	imageFitStretch = "stretch"
+	imageFitCenter  = "center"
+)
+
+var (
+	imageFits = []string{imageFitContain, imageFitCover, imageFitStretch, imageFitCenter}
+
+	// embeddableImageTypes are embedded as is, every other supported type is transcoded to PNG
+	embeddableImageTypes = map[string]string{
+		"image/png":  "png",
+		"image/jpeg": "jpg",
+	}
+	transcodedImageTypes = []string{"image/gif", "image/bmp", "image/webp", "image/tiff"}
+
+	errInvalidImage = errors.New("image file is not a valid image, must be one of: PNG, JPEG, GIF, BMP, WebP, TIFF")
+)
+
+// pdfImages describes the images converted to a PDF, one per page
+type pdfImages struct {
+	filenames  []string
+	fit        string
+	dpi        float64
+	autoRotate bool
+}
+
+// expandPDFImages reads the image configuration of a mailform_pdf resource
+func expandPDFImages(d resourceGetter) pdfImages {
+	images := pdfImages{
+		filenames:  expandStringList(d.Get("images").([]any)),
+		fit:        d.Get("image_fit").(string),
+		dpi:        d.Get("image_dpi").(float64),
+		autoRotate: d.Get("auto_rotate").(bool),
+	}
+
+	if imageFilename := d.Get("image_filename").(string); imageFilename != "" {
+		images.filenames = []string{imageFilename}
+	}
+
+	return images
+}
+
+// detectImageType sniffs the content type of an image from its first bytes
+func detectImageType(buf []byte) string {
+	// TIFF is not sniffed by net/http
+	if bytes.HasPrefix(buf, []byte("II*\x00")) || bytes.HasPrefix(buf, []byte("MM\x00*")) {
+		return "image/tiff"
+	}
+	return http.DetectContentType(buf)
+}
+
+// validateImageFile ensures a file exists and is an image that can be embedded in a PDF
+func validateImageFile(val any, key string) (warns []string, errs []error) {
+	buf := make([]byte, 512)
+
+	imageFilename := val.(string)
+	file, err := os.Open(imageFilename)
+	if err != nil {
+		errs = append(errs, err)
+		return warns, errs
+	}
+
+	defer file.Close()
+
+	_, err = file.Read(buf)
+	if err != nil {
+		errs = append(errs, err)
+		return warns, errs
+	}
+
+	contentType := detectImageType(buf)
+
+	if _, ok := embeddableImageTypes[contentType]; !ok && !slices.Contains(transcodedImageTypes, contentType) {
+		errs = append(errs, errInvalidImage)
+		return warns, errs
+	}
+
+	return warns, errs
+}
+
+// imageConfig returns the pixel dimensions of an image file
+func imageConfig(filename string) (image.Config, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("unable to read image %s: %w", filename, err)
+	}
+	return config, nil
+}
+
+// registerImage embeds an image in the document, transcoding types gofpdf cannot read
+// and rotating it a quarter turn clockwise when requested. It returns the registered image name.
+func registerImage(pdf *gofpdf.Fpdf, filename string, rotate bool) (string, *gofpdf.ImageInfoType, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contentType := detectImageType(data)
+	imageType, embeddable := embeddableImageTypes[contentType]
+	if !embeddable && !slices.Contains(transcodedImageTypes, contentType) {
+		return "", nil, fmt.Errorf("%s: %w", filename, errInvalidImage)
+	}
+
+	name := filename
+	if !embeddable || rotate {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to decode image %s: %w", filename, err)
+		}
+		if rotate {
+			img = rotateClockwise(img)
+			name += "#rotated"
+		}
+
+		var buf bytes.Buffer
+		err = png.Encode(&buf, img)
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to transcode image %s: %w", filename, err)
+		}
+		data, imageType = buf.Bytes(), "png"
+	}
+
+	info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType, ReadDpi: true}, bytes.NewReader(data))
+	if pdf.Err() {
+		return "", nil, pdf.Error()
+	}
+
+	return name, info, nil
+}
+
+// rotateClockwise rotates an image a quarter turn clockwise
+func rotateClockwise(img image.Image) image.Image {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	rotated := image.NewRGBA(image.Rect(0, 0, bounds.Dy(), bounds.Dx()))
+	for x := 0; x < bounds.Dx(); x++ {
+		for y := 0; y < bounds.Dy(); y++ {
+			rotated.Set(bounds.Dy()-1-y, x, src.At(x, y))
+		}
+	}
+	return rotated
+}
+
+// fitImage positions an image of the given natural size within box
+func fitImage(fit string, width, height float64, box pdfRect) pdfRect {
+	scale := 1.0
+	switch fit {
+	case imageFitStretch:
+		return box
+	case imageFitContain:
+		scale = minFloat(box.width/width, box.height/height)
+	case imageFitCover:
+		scale = maxFloat(box.width/width, box.height/height)
+	}
+
+	width, height = width*scale, height*scale
+	return pdfRect{
+		x:      box.x + (box.width-width)/2,
+		y:      box.y + (box.height-height)/2,
+		width:  width,
+		height: height,
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// render adds a page for each image, fitting the image within the layout's margins
+func (i pdfImages) render(pdf *gofpdf.Fpdf, layout pdfLayout) error {
+	x, y, width, height := layout.contentBox(unprintableMargin)
+	box := pdfRect{x: x, y: y, width: width, height: height}
+
+	for _, filename := range i.filenames {
+		pdf.AddPage()
+
+		rotate := false
+		if i.autoRotate {
+			config, err := imageConfig(filename)
+			if err != nil {
+				return err
+			}
+			// Rotate when the image and page orientations differ, square images are left alone
+			rotate = config.Width != config.Height && (config.Width > config.Height) != (box.width > box.height)
+		}
+
+		name, info, err := registerImage(pdf, filename, rotate)
+		if err != nil {
+			return err
+		}
+		if i.dpi > 0 {
+			info.SetDpi(i.dpi)
+		}
+
+		placement := fitImage(i.fit, info.Width(), info.Height(), box)
+		// Images that overflow the box when covering or centering are cropped to it
+		pdf.ClipRect(box.x, box.y, box.width, box.height, false)
+		pdf.ImageOptions(name, placement.x, placement.y, placement.width, placement.height, false, gofpdf.ImageOptions{}, 0, "")
+		pdf.ClipEnd()
+	}
+
+	return pdf.Error()
+}
+
+// imageSchema describes the image to PDF conversion inputs of a mailform_pdf resource
+var imageSchema = map[string]*schema.Schema{
+	"images": {
+		Description: "Image files to be converted to a PDF, one per page. Supports PNG, JPEG, GIF, BMP, WebP and TIFF.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		MinItems:    1,
+		ConflictsWith: []string{
+			"image_filename",
+			"header",
+			"content",
+			"font_family",
+			"font_file",
+		},
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validateImageFile,
+		},
+	},
+	"image_fit": {
+		Description:  fmt.Sprintf("How images are fit within the margins of each page. Must be one of: `%s`. `center` prints images at their natural size based on `image_dpi`. Defaults to `%s`.", strings.Join(imageFits, "`, `"), imageFitStretch),
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      imageFitStretch,
+		ValidateFunc: validation.StringInSlice(imageFits, false),
+	},
+	"image_dpi": {
+		Description:  "Resolution of images in dots per inch, used to calculate their natural size. Defaults to the resolution stored in PNG images, otherwise `72`.",
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	},
+	"auto_rotate": {
+		Description: "Rotate images a quarter turn when their orientation does not match the page's.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+	},
+}
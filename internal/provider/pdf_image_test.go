@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+func TestFitImage(t *testing.T) {
+	box := pdfRect{x: 0, y: 0, width: 100, height: 200}
+
+	tests := []struct {
+		fit      string
+		expected pdfRect
+	}{
+		{fit: imageFitStretch, expected: box},
+		{fit: imageFitContain, expected: pdfRect{x: 0, y: 87.5, width: 100, height: 25}},
+		{fit: imageFitCover, expected: pdfRect{x: -350, y: 0, width: 800, height: 200}},
+		{fit: imageFitCenter, expected: pdfRect{x: 30, y: 95, width: 40, height: 10}},
+	}
+
+	for _, test := range tests {
+		actual := fitImage(test.fit, 40, 10, box)
+		if !floatEquals(actual.x, test.expected.x) || !floatEquals(actual.y, test.expected.y) ||
+			!floatEquals(actual.width, test.expected.width) || !floatEquals(actual.height, test.expected.height) {
+			t.Errorf("%s: expected %+v, got %+v", test.fit, test.expected, actual)
+		}
+	}
+}
+
+func TestPDFImagesRender(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewPaletted(image.Rect(0, 0, 300, 200), []color.Color{color.White, color.Black})
+
+	var gifBuf, bmpBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, img, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := bmp.Encode(&bmpBuf, img); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	gifFile, bmpFile := filepath.Join(dir, "image.gif"), filepath.Join(dir, "image.bmp")
+	if err := os.WriteFile(gifFile, gifBuf.Bytes(), 0o644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.WriteFile(bmpFile, bmpBuf.Bytes(), 0o644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, filename := range []string{gifFile, bmpFile} {
+		if _, errs := validateImageFile(filename, "images"); len(errs) > 0 {
+			t.Errorf("expected %s to be valid, got %v", filename, errs)
+		}
+	}
+
+	opts := pdfOptions{
+		layout: testPDFLayout(defaultPageSize, orientationPortrait),
+		images: pdfImages{
+			filenames:  []string{writeTestImage(t, 200, 100), gifFile, bmpFile},
+			fit:        imageFitContain,
+			autoRotate: true,
+		},
+	}
+
+	pdf := opts.layout.newDocument(0)
+	if err := opts.images.render(pdf, opts.layout); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if pages := pdf.PageCount(); pages != 3 {
+		t.Errorf("expected one page per image, got %d", pages)
+	}
+
+	// Landscape images are rotated to fill the portrait page
+	info := pdf.GetImageInfo(gifFile + "#rotated")
+	if info == nil || info.Width() >= info.Height() {
+		t.Errorf("expected %s to be rotated to portrait", gifFile)
+	}
+
+	if _, err := renderPDFContent(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestValidateImageFile(t *testing.T) {
+	if got := detectImageType([]byte("II*\x00rest")); got != "image/tiff" {
+		t.Errorf("expected TIFF to be detected, got %s", got)
+	}
+
+	text := filepath.Join(t.TempDir(), "image.txt")
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, errs := validateImageFile(text, "images"); len(errs) == 0 {
+		t.Error("expected text file to be invalid")
+	}
+}
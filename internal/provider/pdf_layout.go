@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	pageSizeCustom       = "Custom"
+	orientationPortrait  = "portrait"
+	orientationLandscape = "landscape"
+	defaultPageSize      = "Letter"
+	defaultMargin        = 10.0
+	defaultFontSize      = 11.0
+	defaultLineHeight    = 8.0
+	headerFontSize       = 16.0
+	headerLineHeight     = 9.0
+	headerSpacing        = 10.0
+	pdfUnit              = "mm"
+	mmPerInch            = 25.4
+)
+
+var (
+	// pageSizes are the portrait dimensions of supported pages in millimeters
+	pageSizes = map[string]gofpdf.SizeType{
+		"Letter":       {Wd: 8.5 * mmPerInch, Ht: 11 * mmPerInch},
+		"Legal":        {Wd: 8.5 * mmPerInch, Ht: 14 * mmPerInch},
+		"A4":           {Wd: 210, Ht: 297},
+		"A5":           {Wd: 148, Ht: 210},
+		"Postcard4x6":  {Wd: 4 * mmPerInch, Ht: 6 * mmPerInch},
+		"Postcard6x9":  {Wd: 6 * mmPerInch, Ht: 9 * mmPerInch},
+		"Postcard6x11": {Wd: 6 * mmPerInch, Ht: 11 * mmPerInch},
+	}
+	orientations = map[string]string{
+		orientationPortrait:  gofpdf.OrientationPortrait,
+		orientationLandscape: gofpdf.OrientationLandscape,
+	}
+
+	errMarginsTooLarge = errors.New("margins leave no printable area on the page")
+)
+
+// pageSizeNames returns the supported page_size values in a stable order
+func pageSizeNames() []string {
+	names := maps.Keys(pageSizes)
+	slices.Sort(names)
+	return append(names, pageSizeCustom)
+}
+
+// pdfMargins are the distances in millimeters between the page edges and its content
+type pdfMargins struct {
+	top, right, bottom, left float64
+}
+
+// pdfLayout describes the page geometry and text metrics of a PDF
+type pdfLayout struct {
+	pageSize    string
+	size        gofpdf.SizeType
+	orientation string
+	// margins is nil when not configured so that images may default to the unprintable margin
+	margins    *pdfMargins
+	fontSize   float64
+	lineHeight float64
+}
+
+// expandPDFLayout reads the page layout configuration of a mailform_pdf resource
+func expandPDFLayout(d resourceGetter) pdfLayout {
+	layout := pdfLayout{
+		pageSize:    d.Get("page_size").(string),
+		orientation: d.Get("orientation").(string),
+		fontSize:    d.Get("font_size").(float64),
+		lineHeight:  d.Get("line_height").(float64),
+	}
+
+	if layout.pageSize == pageSizeCustom {
+		layout.size = gofpdf.SizeType{
+			Wd: d.Get("page_width").(float64),
+			Ht: d.Get("page_height").(float64),
+		}
+	} else {
+		layout.size = pageSizes[layout.pageSize]
+	}
+
+	if margins := d.Get("margins").([]any); len(margins) > 0 && margins[0] != nil {
+		m := margins[0].(map[string]any)
+		layout.margins = &pdfMargins{
+			top:    m["top"].(float64),
+			right:  m["right"].(float64),
+			bottom: m["bottom"].(float64),
+			left:   m["left"].(float64),
+		}
+	}
+
+	return layout
+}
+
+// pageDimensions returns the dimensions of the page after applying orientation
+func (l pdfLayout) pageDimensions() (width, height float64) {
+	width, height = l.size.Wd, l.size.Ht
+	if l.orientation == orientationLandscape {
+		return height, width
+	}
+	return width, height
+}
+
+// marginsOrDefault returns the configured margins, or fallback on every side if none were configured
+func (l pdfLayout) marginsOrDefault(fallback float64) pdfMargins {
+	if l.margins != nil {
+		return *l.margins
+	}
+	return pdfMargins{top: fallback, right: fallback, bottom: fallback, left: fallback}
+}
+
+// validate ensures the layout describes a printable page
+func (l pdfLayout) validate() error {
+	if l.size.Wd <= 0 || l.size.Ht <= 0 {
+		return fmt.Errorf("page_width and page_height are required when page_size is %q", l.pageSize)
+	}
+
+	width, height := l.pageDimensions()
+	m := l.marginsOrDefault(defaultMargin)
+	if m.left+m.right >= width || m.top+m.bottom >= height {
+		return errMarginsTooLarge
+	}
+
+	return nil
+}
+
+// newDocument creates an empty gofpdf document using the layout's page geometry.
+// Text documents fall back to default margins when none are configured.
+func (l pdfLayout) newDocument(fallbackMargin float64) *gofpdf.Fpdf {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientations[l.orientation],
+		UnitStr:        pdfUnit,
+		Size:           l.size,
+	})
+
+	m := l.marginsOrDefault(fallbackMargin)
+	pdf.SetMargins(m.left, m.top, m.right)
+	pdf.SetAutoPageBreak(true, m.bottom)
+
+	return pdf
+}
+
+// contentBox returns the position and size of the area within the margins of a page
+func (l pdfLayout) contentBox(fallbackMargin float64) (x, y, width, height float64) {
+	pageWidth, pageHeight := l.pageDimensions()
+	m := l.marginsOrDefault(fallbackMargin)
+	return m.left, m.top, pageWidth - m.left - m.right, pageHeight - m.top - m.bottom
+}
+
+// layoutSchema describes the page geometry inputs of a mailform_pdf resource
+var layoutSchema = map[string]*schema.Schema{
+	"page_size": {
+		Description:  fmt.Sprintf("Size of each page. Must be one of: `%s`. `%s` requires `page_width` and `page_height`. Defaults to `%s`.", strings.Join(pageSizeNames(), "`, `"), pageSizeCustom, defaultPageSize),
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      defaultPageSize,
+		ValidateFunc: validation.StringInSlice(pageSizeNames(), false),
+	},
+	"page_width": {
+		Description:  "Width of each page in millimeters when `page_size` is `Custom`.",
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+		RequiredWith: []string{"page_height"},
+	},
+	"page_height": {
+		Description:  "Height of each page in millimeters when `page_size` is `Custom`.",
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+		RequiredWith: []string{"page_width"},
+	},
+	"orientation": {
+		Description:  fmt.Sprintf("Page orientation. Must be one of: `%s`. Defaults to `%s`.", strings.Join([]string{orientationPortrait, orientationLandscape}, "`, `"), orientationPortrait),
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      orientationPortrait,
+		ValidateFunc: validation.StringInSlice([]string{orientationPortrait, orientationLandscape}, false),
+	},
+	"margins": {
+		Description: fmt.Sprintf("Page margins in millimeters. Text defaults to `%v` on every side, images to `%v` so that Mailform's printers do not cut them off.", defaultMargin, unprintableMargin),
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"top":    marginSchema("top"),
+				"right":  marginSchema("right"),
+				"bottom": marginSchema("bottom"),
+				"left":   marginSchema("left"),
+			},
+		},
+	},
+	"font_size": {
+		Description:  fmt.Sprintf("Font size of the content in points. Defaults to `%v`.", defaultFontSize),
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		Default:      defaultFontSize,
+		ValidateFunc: validation.FloatAtLeast(1),
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+	},
+	"line_height": {
+		Description:  fmt.Sprintf("Height of each line of content in millimeters. Defaults to `%v`.", defaultLineHeight),
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		Default:      defaultLineHeight,
+		ValidateFunc: validation.FloatAtLeast(0.1),
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+	},
+}
+
+func marginSchema(side string) *schema.Schema {
+	return &schema.Schema{
+		Description:  fmt.Sprintf("The %s margin in millimeters. Defaults to `%v`.", side, defaultMargin),
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		Default:      defaultMargin,
+		ValidateFunc: validation.FloatAtLeast(0),
+	}
+}
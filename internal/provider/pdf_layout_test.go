@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// testPDFLayout returns the layout of a mailform_pdf using default values
+func testPDFLayout(pageSize, orientation string) pdfLayout {
+	return pdfLayout{
+		pageSize:    pageSize,
+		size:        pageSizes[pageSize],
+		orientation: orientation,
+		fontSize:    defaultFontSize,
+		lineHeight:  defaultLineHeight,
+	}
+}
+
+func TestPDFLayoutPageDimensions(t *testing.T) {
+	tests := []struct {
+		pageSize       string
+		orientation    string
+		expectedWidth  float64
+		expectedHeight float64
+	}{
+		{pageSize: "Letter", orientation: orientationPortrait, expectedWidth: 215.9, expectedHeight: 279.4},
+		{pageSize: "A4", orientation: orientationPortrait, expectedWidth: 210, expectedHeight: 297},
+		{pageSize: "A4", orientation: orientationLandscape, expectedWidth: 297, expectedHeight: 210},
+		{pageSize: "Postcard4x6", orientation: orientationLandscape, expectedWidth: 152.4, expectedHeight: 101.6},
+	}
+
+	for _, test := range tests {
+		layout := testPDFLayout(test.pageSize, test.orientation)
+
+		_, err := renderPDFContent(pdfOptions{header: "Title", content: "Body", font: pdfFont{family: defaultFontFamily}, layout: layout})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		pdf := layout.newDocument(defaultMargin)
+		pdf.AddPage()
+		width, height := pdf.GetPageSize()
+		if !floatEquals(width, test.expectedWidth) || !floatEquals(height, test.expectedHeight) {
+			t.Errorf("%s %s: expected %vx%v, got %vx%v", test.pageSize, test.orientation, test.expectedWidth, test.expectedHeight, width, height)
+		}
+	}
+}
+
+func TestPDFLayoutValidate(t *testing.T) {
+	custom := testPDFLayout(pageSizeCustom, orientationPortrait)
+	if err := custom.validate(); err == nil {
+		t.Error("expected custom page size without dimensions to be invalid")
+	}
+
+	custom.size = gofpdf.SizeType{Wd: 100, Ht: 100}
+	if err := custom.validate(); err != nil {
+		t.Errorf("unexpected err: %s", err)
+	}
+
+	custom.margins = &pdfMargins{top: 10, right: 50, bottom: 10, left: 50}
+	if err := custom.validate(); err != errMarginsTooLarge {
+		t.Errorf("expected %s, got %v", errMarginsTooLarge, err)
+	}
+}
+
+func floatEquals(a, b float64) bool {
+	const epsilon = 0.01
+	return a-b < epsilon && b-a < epsilon
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	letterheadFontSize   = 8.0
+	letterheadLineHeight = 4.0
+	letterheadSpacing    = 4.0
+	pageNumberAlias      = "{nb}"
+)
+
+// pdfLetterhead describes the content repeated on every page of a PDF
+type pdfLetterhead struct {
+	image       string
+	headerText  string
+	footerText  string
+	pageNumbers bool
+}
+
+// expandPDFLetterhead reads the letterhead configuration of a mailform_pdf resource
+func expandPDFLetterhead(d resourceGetter) pdfLetterhead {
+	return pdfLetterhead{
+		image:       d.Get("letterhead_image").(string),
+		headerText:  d.Get("header_text").(string),
+		footerText:  d.Get("footer_text").(string),
+		pageNumbers: d.Get("page_numbers").(bool),
+	}
+}
+
+// apply registers the header and footer hooks of the document. The bottom margin
+// is extended so that body content never flows into the footer.
+// imageRect returns where the letterhead image is printed at the top of each page
+func (l pdfLetterhead) imageRect(layout pdfLayout) (pdfRect, error) {
+	config, err := imageConfig(l.image)
+	if err != nil {
+		return pdfRect{}, err
+	}
+	m := layout.marginsOrDefault(defaultMargin)
+	pageWidth, _ := layout.pageDimensions()
+	width := pageWidth - m.left - m.right
+	return pdfRect{x: m.left, y: m.top, width: width, height: width * float64(config.Height) / float64(config.Width)}, nil
+}
+
+func (l pdfLetterhead) apply(pdf *gofpdf.Fpdf, font pdfFont, tr func(string) string, layout pdfLayout) error {
+	m := layout.marginsOrDefault(defaultMargin)
+	pageWidth, pageHeight := layout.pageDimensions()
+	width := pageWidth - m.left - m.right
+
+	pdf.SetFont(font.family, font.style("I"), letterheadFontSize)
+	footerLines := font.splitText(pdf, tr, l.footerText, width)
+	footerHeight := float64(len(footerLines)) * letterheadLineHeight
+	if l.pageNumbers {
+		pdf.AliasNbPages(pageNumberAlias)
+		footerHeight += letterheadLineHeight
+	}
+	if footerHeight > 0 {
+		footerHeight += letterheadSpacing
+		pdf.SetAutoPageBreak(true, m.bottom+footerHeight)
+	}
+
+	imageName, imageHeight := "", 0.0
+	if l.image != "" {
+		name, info, err := registerImage(pdf, l.image, false)
+		if err != nil {
+			return err
+		}
+		imageName, imageHeight = name, width*info.Height()/info.Width()
+	}
+
+	if l.image != "" || l.headerText != "" {
+		pdf.SetHeaderFuncMode(func() {
+			y := m.top
+			if imageName != "" {
+				pdf.ImageOptions(imageName, m.left, y, width, imageHeight, false, gofpdf.ImageOptions{}, 0, "")
+				y += imageHeight + letterheadSpacing
+			}
+			if l.headerText != "" {
+				pdf.SetFont(font.family, font.style("I"), letterheadFontSize)
+				pdf.SetXY(m.left, y)
+				pdf.MultiCell(width, letterheadLineHeight, tr(l.headerText), "", "C", false)
+				y = pdf.GetY() + letterheadSpacing
+			}
+			// Body content flows beneath the letterhead
+			pdf.SetXY(m.left, y)
+		}, false)
+	}
+
+	if footerHeight > 0 {
+		pdf.SetFooterFunc(func() {
+			pdf.SetFont(font.family, font.style("I"), letterheadFontSize)
+			pdf.SetXY(m.left, pageHeight-m.bottom-footerHeight+letterheadSpacing)
+			for _, line := range footerLines {
+				pdf.CellFormat(width, letterheadLineHeight, line, "", 2, "C", false, 0, "")
+			}
+			if l.pageNumbers {
+				pageNumber := fmt.Sprintf("Page %d of %s", pdf.PageNo(), pageNumberAlias)
+				pdf.CellFormat(width, letterheadLineHeight, pageNumber, "", 2, "R", false, 0, "")
+			}
+		})
+	}
+
+	return nil
+}
+
+// letterheadSchema describes the content repeated on every page of a mailform_pdf resource
+var letterheadSchema = map[string]*schema.Schema{
+	"letterhead_image": {
+		Description: "An image printed across the top of every page, such as a company letterhead.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+		ValidateFunc: validateImageFile,
+	},
+	"header_text": {
+		Description: "Text printed at the top of every page, beneath `letterhead_image`.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+	},
+	"footer_text": {
+		Description: "Text printed at the bottom of every page, such as legal text.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+	},
+	"page_numbers": {
+		Description: "Print \"Page X of Y\" at the bottom of every page.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		ConflictsWith: []string{
+			"image_filename",
+			"images",
+		},
+	},
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestImage writes a solid PNG image of the given size and returns its path
+func writeTestImage(t *testing.T, width, height int) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 0x1f, G: 0x4e, B: 0x79, A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return path
+}
+
+func TestPDFLetterheadApply(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	font := pdfFont{family: defaultFontFamily}
+	letterhead := pdfLetterhead{
+		image:       writeTestImage(t, 400, 50),
+		headerText:  "ACME Corporation",
+		footerText:  "ACME Corporation is a registered trademark.",
+		pageNumbers: true,
+	}
+
+	pdf := layout.newDocument(defaultMargin)
+	pdf.SetCompression(false)
+	tr := font.register(pdf)
+	letterhead.apply(pdf, font, tr, layout)
+	pdf.AddPage()
+
+	// The body must start beneath the letterhead image and header text
+	_, top, _, _ := pdf.GetMargins()
+	if pdf.GetY() <= top+letterheadLineHeight {
+		t.Errorf("expected body to start beneath letterhead, got y=%v", pdf.GetY())
+	}
+
+	pdf.SetFont(font.family, "", defaultFontSize)
+	pdf.Write(defaultLineHeight, strings.Repeat("Lorem ipsum dolor sit amet. ", 400))
+
+	// Auto page breaks must leave room for the footer
+	_, pageHeight := pdf.GetPageSize()
+	_, bottom := pdf.GetAutoPageBreak()
+	if bottom <= defaultMargin || pdf.GetY() > pageHeight-bottom {
+		t.Errorf("expected body to end above footer, got y=%v break=%v", pdf.GetY(), bottom)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	pages := pdf.PageCount()
+	if pages < 2 {
+		t.Fatalf("expected content to span multiple pages, got %d", pages)
+	}
+	for _, expected := range []string{fmt.Sprintf("Page %d of %d", pages, pages), "ACME Corporation is a registered trademark."} {
+		if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+			t.Errorf("expected rendered PDF to contain %q", expected)
+		}
+	}
+}
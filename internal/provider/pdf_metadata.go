@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	pointsPerInch = 72.0
+)
+
+var (
+	// pageObjectPattern matches the dictionary of a single page, but not the /Pages tree
+	pageObjectPattern = regexp.MustCompile(`/Type\s*/Page\b`)
+	mediaBoxPattern   = regexp.MustCompile(`/MediaBox\s*\[\s*(-?[\d.]+)\s+(-?[\d.]+)\s+(-?[\d.]+)\s+(-?[\d.]+)\s*\]`)
+	objectPattern     = regexp.MustCompile(`(?s)(\d+) 0 obj\s*(.*?)\s*endobj`)
+	pagesTypePattern  = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pagesCountPattern = regexp.MustCompile(`/Count\s+(\d+)`)
+	objectStreamRegex = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+
+	// pdfMetadataKeys are the computed attributes describing the rendered file
+	pdfMetadataKeys = []string{"page_count", "file_size", "sha256", "md5", "base64_content", "rendered_page_width", "rendered_page_height", "signature_valid"}
+)
+
+// pdfMetadata describes a rendered PDF file
+type pdfMetadata struct {
+	pageCount int
+	// pageWidth and pageHeight are the dimensions of the first page in millimeters
+	pageWidth  float64
+	pageHeight float64
+	fileSize   int
+	sha256     string
+	md5        string
+	base64     string
+	// signatureValid is true when the PDF carries a valid digital signature
+	signatureValid bool
+}
+
+// newPDFMetadata inspects the content of a PDF
+func newPDFMetadata(content []byte) pdfMetadata {
+	sha256Sum := sha256.Sum256(content)
+	md5Sum := md5.Sum(content)
+
+	metadata := pdfMetadata{
+		pageCount: pdfPageCount(content),
+		fileSize:  len(content),
+		sha256:    hex.EncodeToString(sha256Sum[:]),
+		md5:       hex.EncodeToString(md5Sum[:]),
+		base64:    base64.StdEncoding.EncodeToString(content),
+	}
+	metadata.pageWidth, metadata.pageHeight = firstPageDimensions(content)
+	metadata.signatureValid = verifyPDFSignature(content) == nil
+
+	return metadata
+}
+
+// pdfObject is an indirect object of a PDF
+type pdfObject struct {
+	number int
+	body   []byte
+}
+
+// pdfObjects returns the latest revision of every uncompressed object in a PDF, in the order they
+// were first written. Objects rewritten by incremental updates, such as signatures, replace earlier revisions.
+func pdfObjects(content []byte) []pdfObject {
+	objects := []pdfObject{}
+	index := map[int]int{}
+	for _, match := range objectPattern.FindAllSubmatch(content, -1) {
+		number, _ := strconv.Atoi(string(match[1]))
+		if i, ok := index[number]; ok {
+			objects[i].body = match[2]
+			continue
+		}
+		index[number] = len(objects)
+		objects = append(objects, pdfObject{number: number, body: match[2]})
+	}
+	return objects
+}
+
+// pdfPages returns the page objects of a PDF
+func pdfPages(content []byte) []pdfObject {
+	pages := []pdfObject{}
+	for _, object := range pdfObjects(content) {
+		if pageObjectPattern.Match(object.body) {
+			pages = append(pages, object)
+		}
+	}
+	return pages
+}
+
+// firstPageDimensions returns the size of the first page in millimeters. Pages without
+// their own media box inherit the first one declared in the document.
+func firstPageDimensions(content []byte) (float64, float64) {
+	mediaBox := mediaBoxPattern.FindSubmatch(content)
+	if pages := pdfPages(content); len(pages) > 0 {
+		if pageMediaBox := mediaBoxPattern.FindSubmatch(pages[0].body); pageMediaBox != nil {
+			mediaBox = pageMediaBox
+		}
+	}
+	if mediaBox == nil {
+		return 0, 0
+	}
+
+	coordinates := make([]float64, 4)
+	for i := range coordinates {
+		coordinates[i], _ = strconv.ParseFloat(string(mediaBox[i+1]), 64)
+	}
+
+	return pointsToMillimeters(coordinates[2] - coordinates[0]), pointsToMillimeters(coordinates[3] - coordinates[1])
+}
+
+// pdfPageCount returns the number of pages in the page tree of a PDF, falling back to counting page
+// objects. Page trees compressed in object streams are included.
+func pdfPageCount(content []byte) int {
+	count := 0
+	for _, body := range append(pdfObjectBodies(content), decompressObjectStreams(content)...) {
+		for _, loc := range pagesTypePattern.FindAllIndex(body, -1) {
+			// The root of the page tree counts every page beneath it
+			if match := pagesCountPattern.FindSubmatch(enclosingDictionary(body, loc[0])); match != nil {
+				if n, _ := strconv.Atoi(string(match[1])); n > count {
+					count = n
+				}
+			}
+		}
+	}
+	if count == 0 {
+		count = len(pdfPages(content))
+	}
+	return count
+}
+
+// pdfObjectBodies returns the bodies of the uncompressed objects of a PDF
+func pdfObjectBodies(content []byte) [][]byte {
+	bodies := [][]byte{}
+	for _, object := range pdfObjects(content) {
+		bodies = append(bodies, object.body)
+	}
+	return bodies
+}
+
+// decompressObjectStreams returns the decompressed content of every object stream.
+// Streams that cannot be decompressed are skipped.
+func decompressObjectStreams(content []byte) [][]byte {
+	streams := [][]byte{}
+	for _, body := range pdfObjectBodies(content) {
+		if !objectStreamRegex.Match(streamDictionary(body)) {
+			continue
+		}
+		if data, ok := streamData(body); ok {
+			streams = append(streams, data)
+		}
+	}
+	return streams
+}
+
+// streamDictionary returns the dictionary of an object, which precedes its stream if it has one
+func streamDictionary(body []byte) []byte {
+	if start := bytes.Index(body, []byte("stream")); start >= 0 {
+		return body[:start]
+	}
+	return body
+}
+
+// streamData returns the data of a stream object, decompressing Flate encoded streams.
+// It reports false when the object is not a stream or uses another filter.
+func streamData(body []byte) ([]byte, bool) {
+	start := bytes.Index(body, []byte("stream"))
+	if start < 0 {
+		return nil, false
+	}
+	dictionary := body[:start]
+	data := bytes.TrimLeft(body[start+len("stream"):], "\r\n")
+	if end := bytes.LastIndex(data, []byte("endstream")); end >= 0 {
+		data = bytes.TrimRight(data[:end], "\r\n")
+	}
+
+	if !bytes.Contains(dictionary, []byte("/Filter")) {
+		return data, true
+	}
+	if !bytes.Contains(dictionary, []byte("/FlateDecode")) {
+		return nil, false
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil && len(decompressed) == 0 {
+		return nil, false
+	}
+	return decompressed, true
+}
+
+// enclosingDictionary returns the innermost dictionary containing the offset
+func enclosingDictionary(data []byte, offset int) []byte {
+	start, depth := -1, 0
+	for i := offset; i > 0; i-- {
+		if data[i-1] == '>' && data[i] == '>' {
+			depth++
+			i--
+		} else if data[i-1] == '<' && data[i] == '<' {
+			if depth == 0 {
+				start = i - 1
+				break
+			}
+			depth--
+			i--
+		}
+	}
+	if start < 0 {
+		return data
+	}
+
+	depth = 0
+	for i := start; i+1 < len(data); i++ {
+		if data[i] == '<' && data[i+1] == '<' {
+			depth++
+			i++
+		} else if data[i] == '>' && data[i+1] == '>' {
+			depth--
+			i++
+			if depth == 0 {
+				return data[start : i+1]
+			}
+		}
+	}
+	return data[start:]
+}
+
+// pointsToMillimeters converts PDF user space units to millimeters, rounded to a tenth of a millimeter
+func pointsToMillimeters(points float64) float64 {
+	return math.Round(math.Abs(points)/pointsPerInch*mmPerInch*10) / 10
+}
+
+// values returns the metadata keyed by its computed attribute
+func (m pdfMetadata) values() map[string]any {
+	return map[string]any{
+		"page_count":           m.pageCount,
+		"file_size":            m.fileSize,
+		"sha256":               m.sha256,
+		"md5":                  m.md5,
+		"base64_content":       m.base64,
+		"rendered_page_width":  m.pageWidth,
+		"rendered_page_height": m.pageHeight,
+		"signature_valid":      m.signatureValid,
+	}
+}
+
+// set stores the metadata in the computed attributes of a mailform_pdf resource
+func (m pdfMetadata) set(d *schema.ResourceData) error {
+	values := m.values()
+	for _, key := range pdfMetadataKeys {
+		if err := d.Set(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pdfMetadataSchema describes the computed attributes of a mailform_pdf resource
+var pdfMetadataSchema = map[string]*schema.Schema{
+	"page_count": {
+		Description: "The number of pages in the PDF. Mailform prices orders by page.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	},
+	"file_size": {
+		Description: "The size of the PDF in bytes.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	},
+	"sha256": {
+		Description: "Hex encoded SHA-256 checksum of the PDF.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	},
+	"md5": {
+		Description: "Hex encoded MD5 checksum of the PDF.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	},
+	"base64_content": {
+		Description: "Base64 encoded content of the PDF.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	},
+	"rendered_page_width": {
+		Description: "Width of the first page of the PDF in millimeters.",
+		Type:        schema.TypeFloat,
+		Computed:    true,
+	},
+	"rendered_page_height": {
+		Description: "Height of the first page of the PDF in millimeters.",
+		Type:        schema.TypeFloat,
+		Computed:    true,
+	},
+	"signature_valid": {
+		Description: "True when the PDF carries a digital signature covering the whole file, made while the signing certificate was valid.",
+		Type:        schema.TypeBool,
+		Computed:    true,
+	},
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewPDFMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		layout     pdfLayout
+		rows       int
+		pageCount  int
+		pageWidth  float64
+		pageHeight float64
+	}{
+		{name: "letter", layout: testPDFLayout("Letter", orientationPortrait), pageCount: 1, pageWidth: 215.9, pageHeight: 279.4},
+		{name: "landscape", layout: testPDFLayout("A4", orientationLandscape), pageCount: 1, pageWidth: 297, pageHeight: 210},
+		{name: "multiple pages", layout: testPDFLayout("Letter", orientationPortrait), rows: 100, pageCount: 3, pageWidth: 215.9, pageHeight: 279.4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := pdfOptions{
+				header:       "Header",
+				content:      "Content",
+				font:         pdfFont{family: defaultFontFamily},
+				layout:       test.layout,
+				creationDate: defaultCreationDate,
+			}
+			if test.rows > 0 {
+				opts.tables = []pdfTable{testPDFTable(test.rows)}
+			}
+
+			content, err := renderPDFContent(opts)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			metadata := newPDFMetadata(content)
+			if metadata.pageCount != test.pageCount {
+				t.Errorf("expected %d pages, got %d", test.pageCount, metadata.pageCount)
+			}
+			if !floatEquals(metadata.pageWidth, test.pageWidth) || !floatEquals(metadata.pageHeight, test.pageHeight) {
+				t.Errorf("expected %.1fx%.1fmm page, got %.1fx%.1fmm", test.pageWidth, test.pageHeight, metadata.pageWidth, metadata.pageHeight)
+			}
+			if metadata.fileSize != len(content) {
+				t.Errorf("expected file size %d, got %d", len(content), metadata.fileSize)
+			}
+			if sum := sha256.Sum256(content); metadata.sha256 != hex.EncodeToString(sum[:]) {
+				t.Errorf("unexpected sha256 %s", metadata.sha256)
+			}
+			if len(metadata.md5) != 32 {
+				t.Errorf("unexpected md5 %s", metadata.md5)
+			}
+			if decoded, err := base64.StdEncoding.DecodeString(metadata.base64); err != nil || string(decoded) != string(content) {
+				t.Error("expected base64 content to decode to the PDF")
+			}
+		})
+	}
+}
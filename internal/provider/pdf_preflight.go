@@ -0,0 +1,450 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// postcardMaxPages is the front and back of a postcard
+	postcardMaxPages = 2
+	// pageSizeTolerance is how far in millimeters a page may differ from the expected size
+	pageSizeTolerance = 1.0
+	// unprintableMargin is the band in millimeters along each page edge that Mailform's printers leave blank
+	unprintableMargin = 0.25 * mmPerInch
+)
+
+var (
+	contentsPattern       = regexp.MustCompile(`/Contents\s*(\[[^\]]*\]|\d+\s+\d+\s+R)`)
+	referencePattern      = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	fontTypePattern       = regexp.MustCompile(`/Type\s*/Font\b`)
+	fontSubtypePattern    = regexp.MustCompile(`/Subtype\s*/(\w+)`)
+	baseFontPattern       = regexp.MustCompile(`/BaseFont\s*/([^\s/<>\[\]()]+)`)
+	fontDescriptorPattern = regexp.MustCompile(`/FontDescriptor\s*(\d+)\s+\d+\s+R`)
+	fontFilePattern       = regexp.MustCompile(`/FontFile[23]?\b`)
+	subsetPrefixPattern   = regexp.MustCompile(`^[A-Z]{6}\+`)
+
+	// standardFonts are available to every PDF reader and need not be embedded
+	standardFonts = []string{
+		"Courier", "Courier-Bold", "Courier-BoldOblique", "Courier-Oblique",
+		"Helvetica", "Helvetica-Bold", "Helvetica-BoldOblique", "Helvetica-Oblique",
+		"Times-Roman", "Times-Bold", "Times-BoldItalic", "Times-Italic",
+		"Symbol", "ZapfDingbats",
+	}
+
+	// letterPageSizes are printed without scaling by every service that mails letters
+	letterPageSizes = []string{"Letter", "A4"}
+	// servicePageSizes are the page sizes each service prints without scaling. Services not listed print letters.
+	servicePageSizes = map[string][]string{
+		"USPS_POSTCARD": {"Postcard4x6", "Postcard6x9", "Postcard6x11"},
+	}
+	servicePageLimits = map[string]int{
+		"USPS_POSTCARD": postcardMaxPages,
+	}
+)
+
+// preflightPDF inspects a PDF before it is ordered, returning errors for PDFs the service rejects
+// and warnings for PDFs that are likely to print poorly
+func preflightPDF(content []byte, service string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	limit, ok := servicePageLimits[service]
+	if !ok {
+		limit = mailformMaxPages
+	}
+	if pages := pdfPageCount(content); pages > limit {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "PDF has too many pages",
+			Detail:   fmt.Sprintf("The PDF has %d pages but %s orders accept at most %d.", pages, service, limit),
+		})
+	}
+
+	pages := pdfPages(content)
+	sizes, ok := servicePageSizes[service]
+	if !ok {
+		sizes = letterPageSizes
+	}
+	if mismatched := mismatchedPages(content, pages, sizes); len(mismatched) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "PDF page size does not match the service",
+			Detail:   fmt.Sprintf("Pages %s are not %s in either orientation, so %s orders may scale or crop them.", joinInts(mismatched), strings.Join(sizes, ", "), service),
+		})
+	}
+
+	// The content of encrypted PDFs cannot be inspected
+	if isEncryptedPDF(content) {
+		return diags
+	}
+
+	objects := map[int][]byte{}
+	for _, object := range pdfObjects(content) {
+		objects[object.number] = object.body
+	}
+
+	inMargin, closest := []int{}, unprintableMargin
+	for i, page := range pages {
+		distance, ok := contentEdgeDistance(objects, page.body, pageMediaBox(content, page.body))
+		if ok && distance < unprintableMargin {
+			inMargin = append(inMargin, i+1)
+			closest = math.Min(closest, distance)
+		}
+	}
+	if len(inMargin) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "PDF content is close to the page edge",
+			Detail:   fmt.Sprintf("Content on pages %s comes within an estimated %.1fmm of the page edge. Mailform's printers leave about %.1fmm along each edge blank, so it may be cut off.", joinInts(inMargin), closest, unprintableMargin),
+		})
+	}
+
+	if missing := missingFonts(objects); len(missing) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "PDF fonts are not embedded",
+			Detail:   fmt.Sprintf("Fonts %s are not embedded in the PDF, so the printer may substitute other fonts.", strings.Join(missing, ", ")),
+		})
+	}
+
+	return diags
+}
+
+// pageMediaBox returns the media box of a page in points. Pages without their own media box
+// inherit the first one declared in the document.
+func pageMediaBox(content, page []byte) []float64 {
+	match := mediaBoxPattern.FindSubmatch(page)
+	if match == nil {
+		match = mediaBoxPattern.FindSubmatch(content)
+	}
+	if match == nil {
+		return nil
+	}
+
+	box := make([]float64, 4)
+	for i := range box {
+		box[i], _ = strconv.ParseFloat(string(match[i+1]), 64)
+	}
+	return box
+}
+
+// mismatchedPages returns the 1-based numbers of pages that are none of the named page sizes
+func mismatchedPages(content []byte, pages []pdfObject, sizes []string) []int {
+	mismatched := []int{}
+	for i, page := range pages {
+		box := pageMediaBox(content, page.body)
+		if box == nil {
+			continue
+		}
+		width, height := pointsToMillimeters(box[2]-box[0]), pointsToMillimeters(box[3]-box[1])
+
+		matched := false
+		for _, name := range sizes {
+			size := pageSizes[name]
+			if sizeMatches(width, height, size.Wd, size.Ht) || sizeMatches(width, height, size.Ht, size.Wd) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			mismatched = append(mismatched, i+1)
+		}
+	}
+	return mismatched
+}
+
+func sizeMatches(width, height, expectedWidth, expectedHeight float64) bool {
+	return math.Abs(width-expectedWidth) <= pageSizeTolerance && math.Abs(height-expectedHeight) <= pageSizeTolerance
+}
+
+// contentEdgeDistance estimates the distance in millimeters between the content of a page and the nearest
+// edge of its media box. It reports false when the page has no content that could be inspected.
+func contentEdgeDistance(objects map[int][]byte, page []byte, box []float64) (float64, bool) {
+	match := contentsPattern.FindSubmatch(page)
+	if box == nil || match == nil {
+		return 0, false
+	}
+
+	stream := []byte{}
+	for _, ref := range referencePattern.FindAllSubmatch(match[1], -1) {
+		number, _ := strconv.Atoi(string(ref[1]))
+		if data, ok := streamData(objects[number]); ok {
+			stream = append(append(stream, data...), '\n')
+		}
+	}
+
+	points := contentPoints(stream)
+	if len(points) == 0 {
+		return 0, false
+	}
+
+	distance := math.Inf(1)
+	for _, p := range points {
+		distance = math.Min(distance, math.Min(math.Min(p[0]-box[0], box[2]-p[0]), math.Min(p[1]-box[1], box[3]-p[1])))
+	}
+	// Content beyond the page edge is as close to the edge as content on it
+	return pointsToMillimeters(math.Max(distance, 0)), true
+}
+
+// contentState is the graphics state of a content stream that affects where marks are made
+type contentState struct {
+	ctm         [6]float64
+	whiteFill   bool
+	whiteStroke bool
+}
+
+// contentPoints returns the positions in page space of marks made by a content stream. Paths and
+// images are located by their corners and text by where it starts, so the result is an estimate.
+func contentPoints(stream []byte) [][2]float64 {
+	points := [][2]float64{}
+	path := [][2]float64{}
+	state := contentState{ctm: [6]float64{1, 0, 0, 1, 0, 0}}
+	stack := []contentState{}
+	text := [6]float64{1, 0, 0, 1, 0, 0}
+	line := text
+	operands := []float64{}
+
+	for _, token := range contentTokens(stream) {
+		if number, err := strconv.ParseFloat(token, 64); err == nil {
+			operands = append(operands, number)
+			continue
+		}
+
+		switch token {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(operands) >= 6 {
+				state.ctm = multiplyMatrix(lastOperands(operands, 6), state.ctm)
+			}
+		case "g", "rg", "k":
+			state.whiteFill = isWhite(token, operands)
+		case "G", "RG", "K":
+			state.whiteStroke = isWhite(strings.ToLower(token), operands)
+		case "m", "l":
+			if len(operands) >= 2 {
+				o := lastOperands(operands, 2)
+				path = append(path, transformPoint(state.ctm, o[0], o[1]))
+			}
+		case "c":
+			if len(operands) >= 6 {
+				o := lastOperands(operands, 6)
+				path = append(path, transformPoint(state.ctm, o[0], o[1]), transformPoint(state.ctm, o[2], o[3]), transformPoint(state.ctm, o[4], o[5]))
+			}
+		case "re":
+			if len(operands) >= 4 {
+				o := lastOperands(operands, 4)
+				path = append(path, transformPoint(state.ctm, o[0], o[1]), transformPoint(state.ctm, o[0]+o[2], o[1]+o[3]))
+			}
+		case "f", "F", "f*":
+			if !state.whiteFill {
+				points = append(points, path...)
+			}
+			path = path[:0]
+		case "S", "s":
+			if !state.whiteStroke {
+				points = append(points, path...)
+			}
+			path = path[:0]
+		case "B", "B*", "b", "b*":
+			if !state.whiteFill || !state.whiteStroke {
+				points = append(points, path...)
+			}
+			path = path[:0]
+		case "n":
+			path = path[:0]
+		case "Do":
+			// Images and forms are drawn into the unit square
+			for _, corner := range [][2]float64{{0, 0}, {1, 1}} {
+				points = append(points, transformPoint(state.ctm, corner[0], corner[1]))
+			}
+		case "BT":
+			text = [6]float64{1, 0, 0, 1, 0, 0}
+			line = text
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				o := lastOperands(operands, 2)
+				line = multiplyMatrix([]float64{1, 0, 0, 1, o[0], o[1]}, line)
+				text = line
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				copy(line[:], lastOperands(operands, 6))
+				text = line
+			}
+		case "Tj", "TJ", "'", "\"":
+			if !state.whiteFill {
+				points = append(points, transformPoint(multiplyMatrix(text[:], state.ctm), 0, 0))
+			}
+		}
+		operands = operands[:0]
+	}
+
+	return points
+}
+
+// contentTokens splits a content stream into numbers and operators, dropping strings, names, arrays and inline image data
+func contentTokens(stream []byte) []string {
+	tokens := []string{}
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			depth := 0
+			for ; i < len(stream); i++ {
+				if stream[i] == '\\' {
+					i++
+				} else if stream[i] == '(' {
+					depth++
+				} else if stream[i] == ')' {
+					depth--
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+		case c == '<' && i+1 < len(stream) && stream[i+1] != '<':
+			for i < len(stream) && stream[i] != '>' {
+				i++
+			}
+			i++
+		case bytes.IndexByte([]byte("[]<>{}/"), c) >= 0:
+			// Delimiters are dropped along with the names that follow a slash
+			i++
+			if c == '/' {
+				for i < len(stream) && !isContentDelimiter(stream[i]) {
+					i++
+				}
+			}
+		case isContentWhitespace(c):
+			i++
+		default:
+			start := i
+			for i < len(stream) && !isContentDelimiter(stream[i]) {
+				i++
+			}
+			token := string(stream[start:i])
+			tokens = append(tokens, token)
+			if token == "ID" {
+				// Inline image data runs until the EI operator
+				if end := bytes.Index(stream[i:], []byte("EI")); end >= 0 {
+					i += end + len("EI")
+				} else {
+					i = len(stream)
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+func isContentWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isContentDelimiter(c byte) bool {
+	return isContentWhitespace(c) || bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// isWhite reports whether the operands of a lower case color operator describe white
+func isWhite(operator string, operands []float64) bool {
+	switch operator {
+	case "g":
+		return len(operands) >= 1 && lastOperands(operands, 1)[0] >= 1
+	case "rg":
+		if len(operands) < 3 {
+			return false
+		}
+		o := lastOperands(operands, 3)
+		return o[0] >= 1 && o[1] >= 1 && o[2] >= 1
+	case "k":
+		if len(operands) < 4 {
+			return false
+		}
+		o := lastOperands(operands, 4)
+		return o[0] <= 0 && o[1] <= 0 && o[2] <= 0 && o[3] <= 0
+	}
+	return false
+}
+
+func lastOperands(operands []float64, n int) []float64 {
+	return operands[len(operands)-n:]
+}
+
+// multiplyMatrix returns the product of two PDF transformation matrices [a b c d e f]
+func multiplyMatrix(m []float64, n [6]float64) [6]float64 {
+	return [6]float64{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func transformPoint(m [6]float64, x, y float64) [2]float64 {
+	return [2]float64{x*m[0] + y*m[2] + m[4], x*m[1] + y*m[3] + m[5]}
+}
+
+// missingFonts returns the names of fonts that are neither embedded nor one of the standard fonts
+func missingFonts(objects map[int][]byte) []string {
+	missing := map[string]bool{}
+	for _, body := range objects {
+		dictionary := streamDictionary(body)
+		if !fontTypePattern.Match(dictionary) {
+			continue
+		}
+		// Composite fonts are embedded through their descendant and Type3 fonts are drawn by the PDF itself
+		subtype := fontSubtypePattern.FindSubmatch(dictionary)
+		if subtype == nil || string(subtype[1]) == "Type0" || string(subtype[1]) == "Type3" {
+			continue
+		}
+		baseFont := baseFontPattern.FindSubmatch(dictionary)
+		if baseFont == nil {
+			continue
+		}
+		name := subsetPrefixPattern.ReplaceAllString(string(baseFont[1]), "")
+
+		descriptor := fontDescriptorPattern.FindSubmatch(dictionary)
+		if descriptor == nil {
+			if !slices.Contains(standardFonts, name) {
+				missing[name] = true
+			}
+			continue
+		}
+		number, _ := strconv.Atoi(string(descriptor[1]))
+		if !fontFilePattern.Match(objects[number]) && !slices.Contains(standardFonts, name) {
+			missing[name] = true
+		}
+	}
+
+	names := maps.Keys(missing)
+	slices.Sort(names)
+	return names
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ", ")
+}
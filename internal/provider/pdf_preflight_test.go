@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// testPreflightPDF returns a single Letter page PDF drawing the content stream with font as /F1
+func testPreflightPDF(stream, font string) []byte {
+	objects := []string{
+		"<</Type /Catalog /Pages 2 0 R>>",
+		"<</Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792]>>",
+		"<</Type /Page /Parent 2 0 R /Resources <</Font <</F1 5 0 R>>>> /Contents 4 0 R>>",
+		fmt.Sprintf("<</Length %d>>\nstream\n%s\nendstream", len(stream), stream),
+		font,
+		"<</Type /FontDescriptor /FontName /ABCDEF+Arial /Flags 32>>",
+	}
+
+	var content strings.Builder
+	content.WriteString("%PDF-1.4\n")
+	for i, object := range objects {
+		fmt.Fprintf(&content, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	return []byte(content.String())
+}
+
+func TestPreflightPDF(t *testing.T) {
+	opts := pdfOptions{
+		header:       "Header",
+		content:      "Content",
+		font:         pdfFont{family: defaultFontFamily},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate: defaultCreationDate,
+	}
+	letter, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts.layout = testPDFLayout("Postcard4x6", orientationLandscape)
+	postcard, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts.font = pdfFont{family: "calligra", file: filepath.Join("testdata", "calligra.ttf")}
+	embedded, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts.layout = testPDFLayout(defaultPageSize, orientationPortrait)
+	opts.font = pdfFont{family: defaultFontFamily}
+	opts.content = strings.Repeat("line\n", 60)
+	twoPages, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts.layout = testPDFLayout("A4", orientationPortrait)
+	opts.content = "Content"
+	a4, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	image, err := renderPDFContent(pdfOptions{
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		images:       pdfImages{filenames: []string{writeTestImage(t, 600, 400)}, fit: imageFitStretch},
+		creationDate: defaultCreationDate,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	standardFont := "<</Type /Font /Subtype /Type1 /BaseFont /Helvetica>>"
+	tests := []struct {
+		name     string
+		content  []byte
+		service  string
+		expected []string
+		hasError bool
+	}{
+		{name: "letter", content: letter, service: "USPS_FIRST_CLASS"},
+		{name: "postcard", content: postcard, service: "USPS_POSTCARD"},
+		{name: "embedded font", content: embedded, service: "USPS_POSTCARD"},
+		{name: "letter as postcard", content: letter, service: "USPS_POSTCARD", expected: []string{"PDF page size does not match the service"}},
+		{name: "postcard as letter", content: postcard, service: "USPS_PRIORITY", expected: []string{"PDF page size does not match the service"}},
+		{name: "two page letter", content: twoPages, service: "USPS_FIRST_CLASS"},
+		{name: "a4 letter", content: a4, service: "USPS_CERTIFIED"},
+		{name: "image", content: image, service: "USPS_FIRST_CLASS"},
+		{
+			name:     "postcard page limit",
+			content:  []byte(strings.Replace(string(testPreflightPDF("", standardFont)), "/Count 1", "/Count 3", 1)),
+			service:  "USPS_POSTCARD",
+			expected: []string{"PDF has too many pages", "PDF page size does not match the service"},
+			hasError: true,
+		},
+		{
+			name:     "full bleed",
+			content:  testPreflightPDF("0.2 g 0 0 612 792 re f", standardFont),
+			service:  "USPS_FIRST_CLASS",
+			expected: []string{"PDF content is close to the page edge"},
+		},
+		{
+			name:    "white background",
+			content: testPreflightPDF("1 g 0 0 612 792 re f 0 g BT /F1 12 Tf 72 720 Td (Hello) Tj ET", standardFont),
+			service: "USPS_FIRST_CLASS",
+		},
+		{
+			name:     "scaled image",
+			content:  testPreflightPDF("q 612 0 0 200 0 296 cm /I1 Do Q", standardFont),
+			service:  "USPS_FIRST_CLASS",
+			expected: []string{"PDF content is close to the page edge"},
+		},
+		{
+			name:     "text near the edge",
+			content:  testPreflightPDF("BT /F1 12 Tf 1 0 0 1 72 720 Tm (Hel\\)lo) Tj 0 -710 Td [(W) 120 (orld)] TJ ET", standardFont),
+			service:  "USPS_FIRST_CLASS",
+			expected: []string{"PDF content is close to the page edge"},
+		},
+		{
+			name:     "missing font",
+			content:  testPreflightPDF("BT /F1 12 Tf 72 720 Td (Hello) Tj ET", "<</Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Arial /FontDescriptor 6 0 R>>"),
+			service:  "USPS_FIRST_CLASS",
+			expected: []string{"PDF fonts are not embedded"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diags := preflightPDF(test.content, test.service)
+			summaries := []string{}
+			for _, d := range diags {
+				summaries = append(summaries, d.Summary)
+			}
+			if strings.Join(summaries, "\n") != strings.Join(test.expected, "\n") {
+				t.Errorf("expected %q, got %q", test.expected, summaries)
+			}
+			if diags.HasError() != test.hasError {
+				t.Errorf("expected error %t, got %v", test.hasError, diags)
+			}
+		})
+	}
+}
+
+func TestPreflightPDFDetail(t *testing.T) {
+	content := testPreflightPDF("0 g 9 300 100 100 re f", "<</Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Arial /FontDescriptor 6 0 R>>")
+
+	diags := preflightPDF(content, "USPS_FIRST_CLASS")
+	if len(diags) != 2 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected two warnings, got %v", diags)
+	}
+	if expected := "Content on pages 1 comes within an estimated 3.2mm of the page edge."; !strings.HasPrefix(diags[0].Detail, expected) {
+		t.Errorf("expected detail to start with %q, got %q", expected, diags[0].Detail)
+	}
+	if expected := "Fonts Arial are not embedded"; !strings.HasPrefix(diags[1].Detail, expected) {
+		t.Errorf("expected detail to start with %q, got %q", expected, diags[1].Detail)
+	}
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	permissionPrint  = "print"
+	permissionCopy   = "copy"
+	permissionModify = "modify"
+)
+
+var (
+	// pdfPermissions maps permissions inputs to gofpdf protection flags
+	pdfPermissions = map[string]byte{
+		permissionPrint:  gofpdf.CnProtectPrint,
+		permissionCopy:   gofpdf.CnProtectCopy,
+		permissionModify: gofpdf.CnProtectModify,
+	}
+
+	encryptPattern = regexp.MustCompile(`/Encrypt\s+\d+\s+\d+\s+R`)
+)
+
+// pdfProtection describes the passwords and permissions a PDF is encrypted with
+type pdfProtection struct {
+	ownerPassword string
+	userPassword  string
+	permissions   []string
+}
+
+// expandPDFProtection reads the encryption configuration of a mailform_pdf resource
+func expandPDFProtection(d resourceGetter) pdfProtection {
+	protection := pdfProtection{
+		ownerPassword: d.Get("owner_password").(string),
+		userPassword:  d.Get("user_password").(string),
+	}
+	if permissions, ok := d.Get("permissions").(*schema.Set); ok {
+		protection.permissions = expandStringList(permissions.List())
+	}
+	return protection
+}
+
+// enabled reports whether the PDF should be encrypted
+func (p pdfProtection) enabled() bool {
+	return p.ownerPassword != "" || p.userPassword != "" || len(p.permissions) > 0
+}
+
+// validate ensures an encrypted PDF has an owner password. gofpdf generates a random one otherwise,
+// which would make the output differ between renders.
+func (p pdfProtection) validate() error {
+	if p.enabled() && p.ownerPassword == "" {
+		return errors.New("owner_password is required when user_password or permissions is set")
+	}
+	return nil
+}
+
+// flags returns the gofpdf protection flags of the permissions
+func (p pdfProtection) flags() byte {
+	var flags byte
+	for _, permission := range p.permissions {
+		flags |= pdfPermissions[permission]
+	}
+	return flags
+}
+
+// apply encrypts the document when protection is enabled
+func (p pdfProtection) apply(pdf *gofpdf.Fpdf) {
+	if p.enabled() {
+		pdf.SetProtection(p.flags(), p.userPassword, p.ownerPassword)
+	}
+}
+
+// isEncryptedPDF reports whether a PDF's trailer references an encryption dictionary
+func isEncryptedPDF(content []byte) bool {
+	return encryptPattern.Match(content)
+}
+
+// protectionSchema describes the encryption inputs of a mailform_pdf resource
+var protectionSchema = map[string]*schema.Schema{
+	"owner_password": {
+		Description: "Password granting full access to the PDF regardless of `permissions`. Setting any of `owner_password`, `user_password` or `permissions` encrypts the PDF with 40-bit RC4, which deters casual access but is not strong encryption. Required when `user_password` or `permissions` is set, so that the output is the same between renders.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		ConflictsWith: []string{
+			"digital_signature",
+		},
+	},
+	"user_password": {
+		Description: "Password required to open the PDF. Requires `owner_password`. Mailform may be unable to print PDFs that require a password.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		ConflictsWith: []string{
+			"digital_signature",
+		},
+		RequiredWith: []string{
+			"user_password",
+			"owner_password",
+		},
+	},
+	"permissions": {
+		Description: fmt.Sprintf("Actions allowed without the owner password. Requires `owner_password`. Must be any of: `%s`. Defaults to none when the PDF is encrypted.", strings.Join([]string{permissionPrint, permissionCopy, permissionModify}, "`, `")),
+		Type:        schema.TypeSet,
+		Optional:    true,
+		ConflictsWith: []string{
+			"digital_signature",
+		},
+		RequiredWith: []string{
+			"permissions",
+			"owner_password",
+		},
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringInSlice([]string{permissionPrint, permissionCopy, permissionModify}, false),
+		},
+	},
+}
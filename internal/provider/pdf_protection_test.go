@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPDFProtection(t *testing.T) {
+	opts := pdfOptions{
+		header:       "Header",
+		content:      "Sensitive content",
+		font:         pdfFont{family: defaultFontFamily},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate: defaultCreationDate,
+	}
+
+	plain, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if isEncryptedPDF(plain) {
+		t.Error("expected PDF without protection to be unencrypted")
+	}
+
+	opts.protection = pdfProtection{ownerPassword: "owner", userPassword: "user", permissions: []string{permissionPrint, permissionCopy}}
+	encrypted, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !isEncryptedPDF(encrypted) {
+		t.Error("expected protected PDF to be encrypted")
+	}
+	if !bytes.Contains(encrypted, []byte("/Filter /Standard")) {
+		t.Error("expected standard security handler")
+	}
+	if metadata := newPDFMetadata(encrypted); metadata.pageCount != 1 {
+		t.Errorf("expected encrypted PDF to have 1 page, got %d", metadata.pageCount)
+	}
+
+	again, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(encrypted, again) {
+		t.Error("expected PDFs encrypted with an owner password to be reproducible")
+	}
+}
+
+func TestPDFProtectionValidate(t *testing.T) {
+	tests := []struct {
+		protection pdfProtection
+		valid      bool
+	}{
+		{protection: pdfProtection{}, valid: true},
+		{protection: pdfProtection{ownerPassword: "owner"}, valid: true},
+		{protection: pdfProtection{ownerPassword: "owner", userPassword: "user", permissions: []string{permissionPrint}}, valid: true},
+		{protection: pdfProtection{userPassword: "user"}, valid: false},
+		{protection: pdfProtection{permissions: []string{permissionPrint}}, valid: false},
+	}
+
+	for _, test := range tests {
+		if err := test.protection.validate(); (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid %t, got %v", test.protection, test.valid, err)
+		}
+	}
+}
+
+func TestPDFProtectionFlags(t *testing.T) {
+	tests := []struct {
+		protection pdfProtection
+		enabled    bool
+		flags      byte
+	}{
+		{protection: pdfProtection{}, enabled: false, flags: 0},
+		{protection: pdfProtection{ownerPassword: "owner"}, enabled: true, flags: 0},
+		{protection: pdfProtection{permissions: []string{permissionPrint}}, enabled: true, flags: 4},
+		{protection: pdfProtection{permissions: []string{permissionPrint, permissionCopy, permissionModify}}, enabled: true, flags: 28},
+	}
+
+	for _, test := range tests {
+		if enabled := test.protection.enabled(); enabled != test.enabled {
+			t.Errorf("%+v: expected enabled %t, got %t", test.protection, test.enabled, enabled)
+		}
+		if flags := test.protection.flags(); flags != test.flags {
+			t.Errorf("%+v: expected flags %d, got %d", test.protection, test.flags, flags)
+		}
+	}
+}
@@ -0,0 +1,496 @@
+package provider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+	"go.mozilla.org/pkcs7"
+)
+
+const (
+	signatureFontSize   = 9.0
+	signatureLineHeight = 4.0
+	// signatureContentsLength is the number of bytes reserved in the PDF for the PKCS#7 signature
+	signatureContentsLength = 8192
+	byteRangePlaceholder    = "/ByteRange [0 0000000000 0000000000 0000000000]"
+)
+
+var (
+	trailerSizePattern  = regexp.MustCompile(`/Size (\d+)`)
+	trailerRootPattern  = regexp.MustCompile(`/Root (\d+) 0 R`)
+	trailerInfoPattern  = regexp.MustCompile(`/Info (\d+) 0 R`)
+	startXrefPattern    = regexp.MustCompile(`startxref\s+(\d+)`)
+	byteRangePattern    = regexp.MustCompile(`/ByteRange \[(\d+) (\d+) (\d+) (\d+)\]`)
+	annotsPattern       = regexp.MustCompile(`/Annots\s*\[`)
+	errSignatureMissing = errors.New("PDF is not digitally signed")
+)
+
+// pdfSignatureStamp is a handwritten signature image stamped on a page, with the signer's printed name and date beneath it
+type pdfSignatureStamp struct {
+	image string
+	page  int
+	rect  pdfRect
+	name  string
+	date  string
+}
+
+// expandPDFSignatureStamp reads the signature block of a mailform_pdf resource, if any
+func expandPDFSignatureStamp(d resourceGetter) *pdfSignatureStamp {
+	signatures := d.Get("signature").([]any)
+	if len(signatures) == 0 || signatures[0] == nil {
+		return nil
+	}
+
+	m := signatures[0].(map[string]any)
+	return &pdfSignatureStamp{
+		image: m["image"].(string),
+		page:  m["page"].(int),
+		rect:  pdfRect{x: m["x"].(float64), y: m["y"].(float64), width: m["width"].(float64), height: m["height"].(float64)},
+		name:  m["name"].(string),
+		date:  m["date"].(string),
+	}
+}
+
+// text returns the lines printed beneath the signature image
+func (s *pdfSignatureStamp) text() []string {
+	text := []string{}
+	for _, line := range []string{s.name, s.date} {
+		if line != "" {
+			text = append(text, line)
+		}
+	}
+	return text
+}
+
+// imageRect returns the region of the signature image. Without a height, the image keeps its aspect ratio.
+func (s *pdfSignatureStamp) imageRect() (pdfRect, error) {
+	rect := s.rect
+	if rect.height == 0 {
+		config, err := imageConfig(s.image)
+		if err != nil {
+			return rect, err
+		}
+		rect.height = rect.width * float64(config.Height) / float64(config.Width)
+	}
+	return rect, nil
+}
+
+// validate ensures the signature and its text lie within the page
+func (s *pdfSignatureStamp) validate(layout pdfLayout) error {
+	rect, err := s.imageRect()
+	if err != nil {
+		return err
+	}
+
+	width, height := layout.pageDimensions()
+	bottom := rect.bottom() + float64(len(s.text()))*signatureLineHeight
+	if rect.x+rect.width > width || bottom > height {
+		return fmt.Errorf("signature extends beyond the %.1fx%.1fmm page", width, height)
+	}
+
+	return nil
+}
+
+// render stamps the signature image on its page and prints the name and date beneath it
+func (s *pdfSignatureStamp) render(pdf *gofpdf.Fpdf, font pdfFont, tr func(string) string) error {
+	rect, err := s.imageRect()
+	if err != nil {
+		return err
+	}
+
+	lastPage := pdf.PageNo()
+	if s.page > lastPage {
+		return fmt.Errorf("signature is placed on page %d but the PDF only has %d pages", s.page, lastPage)
+	}
+	pdf.SetPage(s.page)
+	defer pdf.SetPage(lastPage)
+
+	name, _, err := registerImage(pdf, s.image, false)
+	if err != nil {
+		return err
+	}
+	pdf.ImageOptions(name, rect.x, rect.y, rect.width, rect.height, false, gofpdf.ImageOptions{}, 0, "")
+
+	// Text is positioned explicitly so that it never triggers a page break
+	_, breakMargin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, breakMargin)
+	defer pdf.SetAutoPageBreak(true, breakMargin)
+
+	pdf.SetFont(font.family, font.style(""), signatureFontSize)
+	for i, line := range s.text() {
+		pdf.SetXY(rect.x, rect.bottom()+float64(i)*signatureLineHeight)
+		pdf.CellFormat(rect.width, signatureLineHeight, tr(line), "T", 0, "L", false, 0, "")
+	}
+
+	return pdf.Error()
+}
+
+// pdfDigitalSignature signs a rendered PDF with a local certificate and private key
+type pdfDigitalSignature struct {
+	certificateFile string
+	privateKeyFile  string
+	reason          string
+}
+
+// expandPDFDigitalSignature reads the digital_signature block of a mailform_pdf resource, if any
+func expandPDFDigitalSignature(d resourceGetter) *pdfDigitalSignature {
+	signatures := d.Get("digital_signature").([]any)
+	if len(signatures) == 0 || signatures[0] == nil {
+		return nil
+	}
+
+	m := signatures[0].(map[string]any)
+	return &pdfDigitalSignature{
+		certificateFile: m["certificate_file"].(string),
+		privateKeyFile:  m["private_key_file"].(string),
+		reason:          m["reason"].(string),
+	}
+}
+
+// validateReproducible ensures signing gives the same bytes every time. RSA PKCS #1 v1.5 signatures are
+// deterministic, while Go randomizes ECDSA signatures.
+func (s *pdfDigitalSignature) validateReproducible() error {
+	_, key, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := key.(*rsa.PrivateKey); !ok {
+		return fmt.Errorf("private key %s is not an RSA key. Other signatures differ between renders, which would change content_base64 and replace orders using it on every plan. Use an RSA key, or sign with the mailform_pdf resource", s.privateKeyFile)
+	}
+	return nil
+}
+
+// load reads the PEM encoded certificate and private key, ensuring they belong together
+func (s *pdfDigitalSignature) load() (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := readPEM(s.certificateFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(certPEM.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse certificate %s: %w", s.certificateFile, err)
+	}
+
+	keyPEM, err := readPEM(s.privateKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse private key %s: %w", s.privateKeyFile, err)
+	}
+
+	publicKey, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(cert.PublicKey) {
+		return nil, nil, fmt.Errorf("private key %s does not match certificate %s", s.privateKeyFile, s.certificateFile)
+	}
+
+	return cert, key, nil
+}
+
+// readPEM reads the first PEM block of a file
+func readPEM(filename string) (*pem.Block, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", filename)
+	}
+	return block, nil
+}
+
+// parsePrivateKey parses PKCS#1, PKCS#8 and SEC 1 encoded private keys
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+// sign appends an incremental update to a PDF containing a detached PKCS#7 signature over the whole file.
+// The signature is referenced by an invisible signature field on the first page and dated signedAt.
+// It has no signed attributes, which would embed the current time, so RSA signed PDFs are reproducible.
+func (s *pdfDigitalSignature) sign(content []byte, signedAt time.Time) ([]byte, error) {
+	cert, key, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := trailerInt(content, trailerSizePattern)
+	if err != nil {
+		return nil, err
+	}
+	root, err := trailerInt(content, trailerRootPattern)
+	if err != nil {
+		return nil, err
+	}
+	prevXref, err := trailerInt(content, startXrefPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := map[int][]byte{}
+	for _, object := range pdfObjects(content) {
+		objects[object.number] = object.body
+	}
+	pages := pdfPages(content)
+	if len(pages) == 0 || objects[root] == nil {
+		return nil, errors.New("unable to sign PDF: page tree not found")
+	}
+	page := pages[0]
+	sigNumber, fieldNumber := size, size+1
+
+	// The page and catalog are rewritten to reference the signature field
+	pageBody := page.body
+	fieldRef := fmt.Sprintf("%d 0 R", fieldNumber)
+	if loc := annotsPattern.FindIndex(pageBody); loc != nil {
+		pageBody = concat(pageBody[:loc[1]], []byte(fieldRef+" "), pageBody[loc[1]:])
+	} else {
+		pageBody = appendToDictionary(pageBody, "/Annots ["+fieldRef+"]")
+	}
+	catalogBody := appendToDictionary(objects[root], fmt.Sprintf("/AcroForm <</Fields [%s] /SigFlags 3>>", fieldRef))
+
+	sigBody := fmt.Sprintf("<</Type /Sig\n/Filter /Adobe.PPKLite\n/SubFilter /adbe.pkcs7.detached\n%s\n/Contents <%s>\n/M %s\n/Name %s",
+		byteRangePlaceholder, strings.Repeat("0", signatureContentsLength*2), pdfString(pdfDate(signedAt)), pdfString(cert.Subject.CommonName))
+	if s.reason != "" {
+		sigBody += "\n/Reason " + pdfString(s.reason)
+	}
+	sigBody += ">>"
+	fieldBody := fmt.Sprintf("<</Type /Annot\n/Subtype /Widget\n/FT /Sig\n/T (Signature1)\n/F 132\n/Rect [0 0 0 0]\n/V %d 0 R\n/P %d 0 R>>", sigNumber, page.number)
+
+	var buf bytes.Buffer
+	buf.Write(content)
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	updates := []pdfObject{
+		{number: page.number, body: pageBody},
+		{number: root, body: catalogBody},
+		{number: sigNumber, body: []byte(sigBody)},
+		{number: fieldNumber, body: []byte(fieldBody)},
+	}
+	offsets := make([]int, len(updates))
+	for i, object := range updates {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", object.number, object.body)
+	}
+
+	xref := buf.Len()
+	buf.WriteString("xref\n0 1\n0000000000 65535 f \n")
+	for i, object := range updates {
+		fmt.Fprintf(&buf, "%d 1\n%010d 00000 n \n", object.number, offsets[i])
+	}
+	fmt.Fprintf(&buf, "trailer\n<<\n/Size %d\n/Root %d 0 R\n", size+2, root)
+	if info := trailerInfoPattern.FindAllSubmatch(content, -1); len(info) > 0 {
+		fmt.Fprintf(&buf, "/Info %s 0 R\n", info[len(info)-1][1])
+	}
+	fmt.Fprintf(&buf, "/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n", prevXref, xref)
+
+	signed := buf.Bytes()
+
+	// Everything except the hex encoded signature itself is signed
+	placeholder := bytes.LastIndex(signed, []byte(byteRangePlaceholder))
+	contentsStart := bytes.Index(signed[placeholder:], []byte("/Contents <")) + placeholder + len("/Contents ")
+	contentsEnd := contentsStart + signatureContentsLength*2 + 2
+	byteRange := fmt.Sprintf("/ByteRange [0 %010d %010d %010d]", contentsStart, contentsEnd, len(signed)-contentsEnd)
+	copy(signed[placeholder:], byteRange)
+
+	signedData, err := pkcs7.NewSignedData(concat(signed[:contentsStart], signed[contentsEnd:]))
+	if err != nil {
+		return nil, err
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	err = signedData.SignWithoutAttr(cert, key, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign PDF: %w", err)
+	}
+	signedData.Detach()
+	signature, err := signedData.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign PDF: %w", err)
+	}
+	if len(signature) > signatureContentsLength {
+		return nil, fmt.Errorf("signature is %d bytes which exceeds the %d bytes reserved for it", len(signature), signatureContentsLength)
+	}
+	hex.Encode(signed[contentsStart+1:], signature)
+
+	return signed, nil
+}
+
+// verifyPDFSignature verifies the last digital signature of a PDF. The signature must cover the whole
+// file and the signing certificate must be valid now, as the date of signing is set by creation_date.
+func verifyPDFSignature(content []byte) error {
+	matches := byteRangePattern.FindAllSubmatch(content, -1)
+	if len(matches) == 0 {
+		return errSignatureMissing
+	}
+	byteRange := make([]int, 4)
+	for i := range byteRange {
+		byteRange[i], _ = strconv.Atoi(string(matches[len(matches)-1][i+1]))
+	}
+	start, end := byteRange[1], byteRange[2]
+	if byteRange[0] != 0 || start >= end || end+byteRange[3] != len(content) || content[start] != '<' || content[end-1] != '>' {
+		return errors.New("signature does not cover the whole PDF")
+	}
+
+	der := make([]byte, hex.DecodedLen(end-start-2))
+	_, err := hex.Decode(der, content[start+1:end-1])
+	if err != nil {
+		return fmt.Errorf("unable to decode signature: %w", err)
+	}
+	// The reserved space is padded with zeros after the signature
+	var raw asn1.RawValue
+	_, err = asn1.Unmarshal(der, &raw)
+	if err != nil {
+		return fmt.Errorf("unable to decode signature: %w", err)
+	}
+
+	p7, err := pkcs7.Parse(raw.FullBytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse signature: %w", err)
+	}
+	p7.Content = concat(content[:start], content[end:])
+
+	cert := p7.GetOnlySigner()
+	if cert == nil {
+		return errors.New("signature must have exactly one signer")
+	}
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate %s is not valid between %s and %s", cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+	}
+
+	return p7.Verify()
+}
+
+// trailerInt returns the last value of a trailer entry, the one written by the latest update
+func trailerInt(content []byte, pattern *regexp.Regexp) (int, error) {
+	matches := pattern.FindAllSubmatch(content, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("unable to sign PDF: trailer entry %s not found", pattern)
+	}
+	return strconv.Atoi(string(matches[len(matches)-1][1]))
+}
+
+// appendToDictionary adds entries to the end of a dictionary object
+func appendToDictionary(body []byte, entries string) []byte {
+	end := bytes.LastIndex(body, []byte(">>"))
+	return concat(body[:end], []byte("\n"+entries+"\n>>"), body[end+2:])
+}
+
+// concat joins byte slices into a new slice
+func concat(slices ...[]byte) []byte {
+	return bytes.Join(slices, nil)
+}
+
+// pdfDate formats a time as a PDF date string
+func pdfDate(t time.Time) string {
+	return t.UTC().Format("D:20060102150405Z")
+}
+
+// pdfString encodes text as a PDF literal string
+func pdfString(s string) string {
+	return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+}
+
+// signatureSchema describes a signature image stamped on a mailform_pdf resource
+var signatureSchema = map[string]*schema.Schema{
+	"image": {
+		Description:  "An image of the handwritten signature. A transparent PNG prints best.",
+		Type:         schema.TypeString,
+		Required:     true,
+		ValidateFunc: validateImageFile,
+	},
+	"page": {
+		Description:  "The page to stamp the signature on. Defaults to `1`.",
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      1,
+		ValidateFunc: validation.IntAtLeast(1),
+	},
+	"x": {
+		Description:  "Distance in millimeters from the left edge of the page to the signature.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(0),
+	},
+	"y": {
+		Description:  "Distance in millimeters from the top edge of the page to the signature.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(0),
+	},
+	"width": {
+		Description:  "Width of the signature image in millimeters.",
+		Type:         schema.TypeFloat,
+		Required:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	},
+	"height": {
+		Description:  "Height of the signature image in millimeters. Defaults to preserving the image's aspect ratio.",
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		ValidateFunc: validation.FloatAtLeast(1),
+	},
+	"name": {
+		Description: "The signer's name, printed on a line beneath the signature.",
+		Type:        schema.TypeString,
+		Optional:    true,
+	},
+	"date": {
+		Description: "The date of signing, printed beneath the signer's name.",
+		Type:        schema.TypeString,
+		Optional:    true,
+	},
+}
+
+// digitalSignatureSchema describes the certificate used to digitally sign a mailform_pdf resource
+var digitalSignatureSchema = map[string]*schema.Schema{
+	"certificate_file": {
+		Description: "A PEM encoded X.509 certificate identifying the signer.",
+		Type:        schema.TypeString,
+		Required:    true,
+	},
+	"private_key_file": {
+		Description: "The PEM encoded RSA or ECDSA private key of `certificate_file`.",
+		Type:        schema.TypeString,
+		Required:    true,
+	},
+	"reason": {
+		Description: "The reason for signing, shown by PDF readers.",
+		Type:        schema.TypeString,
+		Optional:    true,
+	},
+}
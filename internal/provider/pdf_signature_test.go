@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its private key, returning their paths
+func writeTestCertificate(t *testing.T, notAfter time.Time) (string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Jane Doe"},
+		NotBefore:    notAfter.Add(-24 * time.Hour * 365),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestPDFDigitalSignatureSign(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, time.Now().Add(24*time.Hour))
+	opts := pdfOptions{
+		header:           "Agreement",
+		content:          "Signed below",
+		font:             pdfFont{family: defaultFontFamily},
+		layout:           testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate:     defaultCreationDate,
+		signature:        &pdfSignatureStamp{image: writeTestImage(t, 300, 100), page: 1, rect: pdfRect{x: 20, y: 200, width: 60}, name: "Jane Doe", date: "2023-03-01"},
+		digitalSignature: &pdfDigitalSignature{certificateFile: certFile, privateKeyFile: keyFile, reason: "Approval (final)"},
+	}
+
+	content, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := verifyPDFSignature(content); err != nil {
+		t.Fatalf("expected valid signature, got: %s", err)
+	}
+	metadata := newPDFMetadata(content)
+	if !metadata.signatureValid {
+		t.Error("expected metadata to report a valid signature")
+	}
+	if metadata.pageCount != 1 {
+		t.Errorf("expected signing to keep 1 page, got %d", metadata.pageCount)
+	}
+	if !bytes.Contains(content, []byte(`/Reason (Approval \(final\))`)) {
+		t.Error("expected escaped reason to be embedded")
+	}
+
+	tampered := bytes.Replace(content, []byte("/Title"), []byte("/Titlf"), 1)
+	if err := verifyPDFSignature(tampered); err == nil {
+		t.Error("expected tampered PDF to fail verification")
+	}
+
+	appended := append(append([]byte{}, content...), []byte("% appended\n")...)
+	if err := verifyPDFSignature(appended); err == nil {
+		t.Error("expected content appended after signing to fail verification")
+	}
+
+	opts.digitalSignature = nil
+	unsigned, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := verifyPDFSignature(unsigned); err != errSignatureMissing {
+		t.Errorf("expected %s, got %v", errSignatureMissing, err)
+	}
+}
+
+func TestPDFDigitalSignatureReproducible(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, time.Now().Add(24*time.Hour))
+	opts := pdfOptions{
+		header:           "Agreement",
+		font:             pdfFont{family: defaultFontFamily},
+		layout:           testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate:     defaultCreationDate,
+		digitalSignature: &pdfDigitalSignature{certificateFile: certFile, privateKeyFile: keyFile},
+	}
+
+	first, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// Renders a second later would embed a different time if the signature were dated when signed
+	time.Sleep(time.Second)
+	second, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("expected signed PDFs to be byte-identical between renders")
+	}
+	if !bytes.Contains(first, []byte("/M "+pdfString(pdfDate(defaultCreationDate)))) {
+		t.Error("expected the signature to be dated creation_date")
+	}
+	if err := opts.digitalSignature.validateReproducible(); err != nil {
+		t.Errorf("expected RSA signatures to be reproducible, got %s", err)
+	}
+}
+
+func TestPDFDigitalSignatureExpired(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, time.Now().Add(-24*time.Hour))
+	opts := pdfOptions{
+		header:           "Agreement",
+		font:             pdfFont{family: defaultFontFamily},
+		layout:           testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate:     defaultCreationDate,
+		digitalSignature: &pdfDigitalSignature{certificateFile: certFile, privateKeyFile: keyFile},
+	}
+
+	content, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if newPDFMetadata(content).signatureValid {
+		t.Error("expected signature from an expired certificate to be invalid")
+	}
+}
+
+func TestPDFDigitalSignatureLoad(t *testing.T) {
+	certFile, _ := writeTestCertificate(t, time.Now().Add(24*time.Hour))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "other.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	signature := &pdfDigitalSignature{certificateFile: certFile, privateKeyFile: keyFile}
+	if _, _, err := signature.load(); err == nil {
+		t.Error("expected a private key of another certificate to be rejected")
+	}
+
+	// ECDSA signatures are randomized
+	template := &x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "Jane Doe"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ecdsaCertFile := filepath.Join(t.TempDir(), "ecdsa.pem")
+	err = os.WriteFile(ecdsaCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ecdsaSignature := &pdfDigitalSignature{certificateFile: ecdsaCertFile, privateKeyFile: keyFile}
+	if _, _, err := ecdsaSignature.load(); err != nil {
+		t.Errorf("err: %s", err)
+	}
+	if err := ecdsaSignature.validateReproducible(); err == nil {
+		t.Error("expected an ECDSA key to be rejected as not reproducible")
+	}
+
+	signature.certificateFile = keyFile
+	if _, _, err := signature.load(); err == nil {
+		t.Error("expected a private key to be rejected as a certificate")
+	}
+}
+
+func TestPDFSignatureStampValidate(t *testing.T) {
+	image := writeTestImage(t, 300, 100)
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+
+	stamp := &pdfSignatureStamp{image: image, page: 1, rect: pdfRect{x: 20, y: 200, width: 60}, name: "Jane Doe"}
+	if err := stamp.validate(layout); err != nil {
+		t.Errorf("err: %s", err)
+	}
+	if rect, _ := stamp.imageRect(); !floatEquals(rect.height, 20) {
+		t.Errorf("expected height to follow the image's aspect ratio, got %.2f", rect.height)
+	}
+
+	// The name line extends beyond the bottom of the page
+	stamp.rect.y = 279.4 - 20
+	if err := stamp.validate(layout); err == nil {
+		t.Error("expected signature beyond the page to be rejected")
+	}
+}
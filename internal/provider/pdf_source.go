@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// mailformMaxPages is the largest number of pages accepted in a single order
+	mailformMaxPages = 100
+	// pdfHeaderSearchLength is how far into a file readers look for the %PDF- header
+	pdfHeaderSearchLength = 1024
+)
+
+var (
+	errNotPDF       = errors.New("file is not a PDF, the %PDF- header is missing")
+	errPDFEncrypted = errors.New("PDF is encrypted, Mailform cannot print encrypted PDFs")
+	errPDFNoPages   = errors.New("PDF has no pages")
+
+	objectHeaderRegex = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+obj\b`)
+	xrefStreamPattern = regexp.MustCompile(`/Type\s*/XRef\b`)
+)
+
+// readSourcePDF reads a finished PDF and ensures Mailform is able to print it
+func readSourcePDF(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateSourcePDF(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return content, nil
+}
+
+// validateSourcePDF checks the header, cross-reference table, encryption and page count of a PDF
+func validateSourcePDF(content []byte) error {
+	header := content
+	if len(header) > pdfHeaderSearchLength {
+		header = header[:pdfHeaderSearchLength]
+	}
+	if !bytes.Contains(header, []byte("%PDF-")) {
+		return errNotPDF
+	}
+
+	err := validateXref(content)
+	if err != nil {
+		return err
+	}
+
+	if isEncryptedPDF(content) {
+		return errPDFEncrypted
+	}
+
+	pages := pdfPageCount(content)
+	if pages == 0 {
+		return errPDFNoPages
+	}
+	if pages > mailformMaxPages {
+		return fmt.Errorf("PDF has %d pages which exceeds the %d pages Mailform accepts", pages, mailformMaxPages)
+	}
+
+	return nil
+}
+
+// validateXref ensures the last cross-reference section of a PDF can be parsed and its entries point at objects
+func validateXref(content []byte) error {
+	offset, err := trailerInt(content, startXrefPattern)
+	if err != nil || offset < 0 || offset >= len(content) {
+		return errors.New("PDF cross-reference table not found")
+	}
+
+	section := content[offset:]
+	// Cross-reference streams are compressed, so only their object header is checked
+	if match := objectHeaderRegex.Find(section); match != nil {
+		end := bytes.Index(section, []byte("stream"))
+		if end < 0 || !xrefStreamPattern.Match(section[:end]) {
+			return errors.New("PDF startxref does not point to a cross-reference stream")
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(section))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "xref" {
+		return errors.New("PDF startxref does not point to a cross-reference table")
+	}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "trailer") {
+			return nil
+		}
+
+		var first, count int
+		if _, err := fmt.Sscanf(line, "%d %d", &first, &count); err != nil {
+			return fmt.Errorf("PDF cross-reference subsection %q is invalid", line)
+		}
+		for i := 0; i < count; i++ {
+			if !scanner.Scan() {
+				return errors.New("PDF cross-reference table is truncated")
+			}
+			err := validateXrefEntry(content, first+i, scanner.Text())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return errors.New("PDF cross-reference table has no trailer")
+}
+
+// validateXrefEntry ensures an in-use cross-reference entry points at the object it names
+func validateXrefEntry(content []byte, number int, entry string) error {
+	fields := strings.Fields(entry)
+	if len(fields) != 3 || (fields[2] != "n" && fields[2] != "f") {
+		return fmt.Errorf("PDF cross-reference entry %q is invalid", entry)
+	}
+	if fields[2] == "f" {
+		return nil
+	}
+
+	offset, err := strconv.Atoi(fields[0])
+	if err != nil || offset < 0 || offset >= len(content) {
+		return fmt.Errorf("PDF cross-reference entry for object %d points outside the file", number)
+	}
+	match := objectHeaderRegex.FindSubmatch(content[offset:])
+	if match == nil || string(match[1]) != strconv.Itoa(number) {
+		return fmt.Errorf("PDF cross-reference entry for object %d does not point at the object", number)
+	}
+
+	return nil
+}
+
+// validateSourceFile ensures a file is a PDF that Mailform is able to print
+func validateSourceFile(val any, key string) (warns []string, errs []error) {
+	_, err := readSourcePDF(val.(string))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return warns, errs
+}
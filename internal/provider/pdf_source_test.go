@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateSourcePDF(t *testing.T) {
+	opts := pdfOptions{
+		header:       "Header",
+		content:      "Content",
+		font:         pdfFont{family: defaultFontFamily},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate: defaultCreationDate,
+	}
+	valid, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := validateSourcePDF(valid); err != nil {
+		t.Errorf("expected rendered PDF to be valid, got: %s", err)
+	}
+
+	opts.protection = pdfProtection{ownerPassword: "owner"}
+	encrypted, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := validateSourcePDF(encrypted); !errors.Is(err, errPDFEncrypted) {
+		t.Errorf("expected %s, got %v", errPDFEncrypted, err)
+	}
+
+	if err := validateSourcePDF([]byte("plain text")); !errors.Is(err, errNotPDF) {
+		t.Errorf("expected %s, got %v", errNotPDF, err)
+	}
+
+	// Point the entry of object 2 at object 1
+	xref := bytes.LastIndex(valid, []byte("\nxref\n")) + 1
+	entries := bytes.SplitAfter(valid[xref:], []byte("\n"))
+	entries[4] = append([]byte{}, entries[3]...)
+	corrupted := append(append([]byte{}, valid[:xref]...), bytes.Join(entries, nil)...)
+	if err := validateSourcePDF(corrupted); err == nil || !strings.Contains(err.Error(), "does not point at the object") {
+		t.Errorf("expected invalid cross-reference offsets to be rejected, got %v", err)
+	}
+
+	entries[4] = []byte("-000000001 00000 n \n")
+	negative := append(append([]byte{}, valid[:xref]...), bytes.Join(entries, nil)...)
+	if err := validateSourcePDF(negative); err == nil || !strings.Contains(err.Error(), "points outside the file") {
+		t.Errorf("expected negative cross-reference offsets to be rejected, got %v", err)
+	}
+
+	shifted := bytes.Replace(valid, []byte("%PDF-1.3\n"), []byte("%PDF-1.3\n%shifted\n"), 1)
+	if err := validateSourcePDF(shifted); err == nil {
+		t.Error("expected shifted objects to be rejected")
+	}
+
+	truncated := valid[:bytes.LastIndex(valid, []byte("trailer"))]
+	if err := validateSourcePDF(truncated); err == nil {
+		t.Error("expected PDF without a trailer to be rejected")
+	}
+}
+
+func TestValidateSourcePDFPageLimit(t *testing.T) {
+	opts := pdfOptions{
+		font:         pdfFont{family: defaultFontFamily},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		content:      strings.Repeat("line\n", 35*(mailformMaxPages+1)),
+		creationDate: defaultCreationDate,
+	}
+	content, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	pages := pdfPageCount(content)
+	if pages <= mailformMaxPages {
+		t.Fatalf("expected more than %d pages, got %d", mailformMaxPages, pages)
+	}
+	expected := fmt.Sprintf("PDF has %d pages which exceeds the %d pages Mailform accepts", pages, mailformMaxPages)
+	if err := validateSourcePDF(content); err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestPDFPageCountObjectStream(t *testing.T) {
+	var stream bytes.Buffer
+	w := zlib.NewWriter(&stream)
+	fmt.Fprint(w, "2 0 3 60 <</Type/Pages/Kids[3 0 R 4 0 R 5 0 R]/Count 3/Resources<</Font<<>>>>>> <</Type/Outlines/Count 12>>")
+	w.Close()
+
+	content := fmt.Sprintf("%%PDF-1.5\n1 0 obj\n<</Type/ObjStm/N 2/First 8/Filter/FlateDecode/Length %d>>\nstream\n%s\nendstream\nendobj\n", stream.Len(), stream.Bytes())
+	if pages := pdfPageCount([]byte(content)); pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+}
@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	tableFontSize   = 10.0
+	tableLineHeight = 6.0
+	tableSpacing    = 6.0
+	// zebraFillLevel is the gray level used to shade every other row
+	zebraFillLevel = 235
+)
+
+var (
+	// tableAlignments maps alignment inputs to gofpdf cell alignment strings
+	tableAlignments = map[string]string{
+		"left":   "L",
+		"center": "C",
+		"right":  "R",
+	}
+)
+
+// pdfTableColumn describes a single column of a table
+type pdfTableColumn struct {
+	header    string
+	width     float64
+	alignment string
+}
+
+// pdfTable is tabular data rendered beneath the content of a PDF
+type pdfTable struct {
+	columns []pdfTableColumn
+	rows    [][]string
+	totals  []string
+	zebra   bool
+}
+
+// expandPDFTables reads the table configuration of a mailform_pdf resource
+func expandPDFTables(d resourceGetter) []pdfTable {
+	tables := []pdfTable{}
+	for _, t := range d.Get("table").([]any) {
+		if t == nil {
+			continue
+		}
+		m := t.(map[string]any)
+
+		table := pdfTable{
+			totals: expandStringList(m["totals"].([]any)),
+			zebra:  m["zebra"].(bool),
+		}
+		for _, c := range m["column"].([]any) {
+			column := c.(map[string]any)
+			table.columns = append(table.columns, pdfTableColumn{
+				header:    column["header"].(string),
+				width:     column["width"].(float64),
+				alignment: column["alignment"].(string),
+			})
+		}
+		for _, row := range m["rows"].([]any) {
+			if row == nil {
+				table.rows = append(table.rows, []string{})
+				continue
+			}
+			table.rows = append(table.rows, expandStringList(row.([]any)))
+		}
+
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// expandStringList converts a list of strings from state, treating null elements as empty strings
+func expandStringList(list []any) []string {
+	values := make([]string, len(list))
+	for i, v := range list {
+		if v != nil {
+			values[i] = v.(string)
+		}
+	}
+	return values
+}
+
+// text returns the text of every row in the table
+func (t pdfTable) text() []string {
+	text := []string{}
+	for _, row := range t.rows {
+		text = append(text, row...)
+	}
+	return text
+}
+
+// boldText returns the text of the header and totals rows, which are printed in bold
+func (t pdfTable) boldText() []string {
+	text := []string{}
+	for _, column := range t.columns {
+		text = append(text, column.header)
+	}
+	return append(text, t.totals...)
+}
+
+// validate ensures every row fits within the table's columns
+func (t pdfTable) validate(layout pdfLayout) error {
+	for i, row := range t.rows {
+		if len(row) > len(t.columns) {
+			return fmt.Errorf("table row %d has %d cells but only %d columns are defined", i, len(row), len(t.columns))
+		}
+	}
+	if len(t.totals) > len(t.columns) {
+		return fmt.Errorf("table totals have %d cells but only %d columns are defined", len(t.totals), len(t.columns))
+	}
+
+	_, _, width, _ := layout.contentBox(defaultMargin)
+	fixed, flexible := 0.0, false
+	for _, column := range t.columns {
+		fixed += column.width
+		flexible = flexible || column.width == 0
+	}
+	if fixed > width || (flexible && fixed >= width) {
+		return fmt.Errorf("table columns are %.1fmm wide which exceeds the %.1fmm between the margins", fixed, width)
+	}
+
+	return nil
+}
+
+// columnWidths returns the width of each column. Columns without a width share the remaining space.
+func (t pdfTable) columnWidths(available float64) []float64 {
+	widths := make([]float64, len(t.columns))
+	flexible := 0
+	for i, column := range t.columns {
+		widths[i] = column.width
+		available -= column.width
+		if column.width == 0 {
+			flexible++
+		}
+	}
+	for i := range widths {
+		if widths[i] == 0 {
+			widths[i] = available / float64(flexible)
+		}
+	}
+	return widths
+}
+
+// render draws the table at the current position, breaking onto new pages and repeating the header row as needed
+func (t pdfTable) render(pdf *gofpdf.Fpdf, font pdfFont, tr func(string) string) {
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, pageHeight := pdf.GetPageSize()
+	_, breakMargin := pdf.GetAutoPageBreak()
+	widths := t.columnWidths(pageWidth - left - right)
+
+	headers := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		headers[i] = column.header
+	}
+
+	// Rows are broken onto new pages manually so that the header row can be repeated
+	pdf.SetAutoPageBreak(false, breakMargin)
+	defer pdf.SetAutoPageBreak(true, breakMargin)
+	pdf.SetFillColor(zebraFillLevel, zebraFillLevel, zebraFillLevel)
+
+	renderRow := func(cells []string, style string, fill bool, border string) {
+		lines, height := t.rowLines(pdf, font, tr, widths, cells, style)
+		if pdf.GetY()+height > pageHeight-breakMargin {
+			pdf.AddPage()
+			if border != "B" {
+				headerLines, headerHeight := t.rowLines(pdf, font, tr, widths, headers, "B")
+				t.renderRow(pdf, widths, headerLines, headerHeight, false, "B")
+				pdf.SetFont(font.family, font.style(style), tableFontSize)
+			}
+		}
+		t.renderRow(pdf, widths, lines, height, fill, border)
+	}
+
+	pdf.Ln(tableSpacing)
+	renderRow(headers, "B", false, "B")
+	for i, row := range t.rows {
+		renderRow(row, "", t.zebra && i%2 == 1, "")
+	}
+	if len(t.totals) > 0 {
+		renderRow(t.totals, "B", false, "T")
+	}
+}
+
+// rowLines sets the font for a row and wraps each of its cells, returning the lines of each cell and the row height
+func (t pdfTable) rowLines(pdf *gofpdf.Fpdf, font pdfFont, tr func(string) string, widths []float64, cells []string, style string) ([][]string, float64) {
+	pdf.SetFont(font.family, font.style(style), tableFontSize)
+
+	lines := make([][]string, len(widths))
+	height := tableLineHeight
+	for i, width := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		lines[i] = font.splitText(pdf, tr, cell, width)
+		if h := float64(len(lines[i])) * tableLineHeight; h > height {
+			height = h
+		}
+	}
+
+	return lines, height
+}
+
+// renderRow draws a row of wrapped cells at the current position. border may be "T" or "B" to rule the top or bottom of the row.
+func (t pdfTable) renderRow(pdf *gofpdf.Fpdf, widths []float64, lines [][]string, height float64, fill bool, border string) {
+	left, y := pdf.GetX(), pdf.GetY()
+
+	x := left
+	for i, width := range widths {
+		if fill {
+			pdf.Rect(x, y, width, height, "F")
+		}
+		for j, line := range lines[i] {
+			pdf.SetXY(x, y+float64(j)*tableLineHeight)
+			pdf.CellFormat(width, tableLineHeight, line, "", 0, tableAlignments[t.alignment(i)], false, 0, "")
+		}
+		x += width
+	}
+
+	switch border {
+	case "T":
+		pdf.Line(left, y, x, y)
+	case "B":
+		pdf.Line(left, y+height, x, y+height)
+	}
+
+	pdf.SetXY(left, y+height)
+}
+
+// alignment returns the alignment of the column at index i
+func (t pdfTable) alignment(i int) string {
+	if t.columns[i].alignment == "" {
+		return "left"
+	}
+	return t.columns[i].alignment
+}
+
+// tableSchema describes a table rendered beneath the content of a mailform_pdf resource
+var tableSchema = map[string]*schema.Schema{
+	"column": {
+		Description: "A column of the table.",
+		Type:        schema.TypeList,
+		Required:    true,
+		MinItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"header": {
+					Description: "Text of the column's header cell, repeated on every page the table spans.",
+					Type:        schema.TypeString,
+					Required:    true,
+				},
+				"width": {
+					Description:  "Width of the column in millimeters. Columns without a width share the remaining space between the margins.",
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validation.FloatAtLeast(0),
+				},
+				"alignment": {
+					Description:  fmt.Sprintf("Alignment of text within the column. Must be one of: `%s`. Defaults to `left`.", strings.Join([]string{"left", "center", "right"}, "`, `")),
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "left",
+					ValidateFunc: validation.StringInSlice([]string{"left", "center", "right"}, false),
+				},
+			},
+		},
+	},
+	"rows": {
+		Description: "Rows of cells, typically from `jsondecode` or `csvdecode`.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeList,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	},
+	"totals": {
+		Description: "Cells of a bold totals row printed after the last row.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	},
+	"zebra": {
+		Description: "Shade every other row.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+	},
+}
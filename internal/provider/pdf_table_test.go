@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func testPDFTable(rows int) pdfTable {
+	table := pdfTable{
+		columns: []pdfTableColumn{
+			{header: "Description"},
+			{header: "Quantity", width: 30, alignment: "right"},
+			{header: "Amount", width: 30, alignment: "right"},
+		},
+		totals: []string{"Total", "", fmt.Sprintf("%d.00", rows)},
+		zebra:  true,
+	}
+	for i := 0; i < rows; i++ {
+		table.rows = append(table.rows, []string{fmt.Sprintf("Line item %d", i), "1", "1.00"})
+	}
+	return table
+}
+
+func TestPDFTableColumnWidths(t *testing.T) {
+	widths := testPDFTable(0).columnWidths(200)
+	expected := []float64{140, 30, 30}
+	for i := range expected {
+		if !floatEquals(widths[i], expected[i]) {
+			t.Errorf("expected widths %v, got %v", expected, widths)
+		}
+	}
+}
+
+func TestPDFTableValidate(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+
+	table := testPDFTable(1)
+	if err := table.validate(layout); err != nil {
+		t.Errorf("unexpected err: %s", err)
+	}
+
+	table.rows = append(table.rows, []string{"a", "b", "c", "d"})
+	if err := table.validate(layout); err == nil {
+		t.Error("expected row with too many cells to be invalid")
+	}
+
+	table = testPDFTable(1)
+	table.columns[1].width = 500
+	if err := table.validate(layout); err == nil {
+		t.Error("expected columns wider than the page to be invalid")
+	}
+}
+
+func TestPDFTableRenderRepeatsHeader(t *testing.T) {
+	layout := testPDFLayout(defaultPageSize, orientationPortrait)
+	font := pdfFont{family: defaultFontFamily}
+	table := testPDFTable(100)
+
+	pdf := layout.newDocument(defaultMargin)
+	pdf.SetCompression(false)
+	tr := font.register(pdf)
+	pdf.AddPage()
+	table.render(pdf, font, tr)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	pages := pdf.PageCount()
+	if pages < 2 {
+		t.Fatalf("expected table to span multiple pages, got %d", pages)
+	}
+	if headers := bytes.Count(buf.Bytes(), []byte("(Description)")); headers != pages {
+		t.Errorf("expected header row on each of %d pages, got %d", pages, headers)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("(100.00)")) {
+		t.Error("expected totals row to be rendered")
+	}
+}
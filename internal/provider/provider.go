@@ -2,14 +2,43 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
 
 	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/cassette"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
 	mailformTokenAPIEnvVar = "MAILFORM_API_TOKEN"
+	mailformBaseURLEnvVar  = "MAILFORM_BASE_URL"
+	// mailformCassetteEnvVar is the path of a cassette to record API interactions to or replay them from
+	mailformCassetteEnvVar = "MAILFORM_CASSETTE"
+	// mailformCassetteModeEnvVar is either record or replay, defaulting to replay
+	mailformCassetteModeEnvVar = "MAILFORM_CASSETTE_MODE"
+)
+
+var (
+	// baseURLDescription is shared by the SDKv2 and framework provider schemas, which must be identical
+	baseURLDescription = fmt.Sprintf("Base URL of the Mailform API, such as a proxy or a fake API for testing. May also be set with the `%s` environment variable. Defaults to `%s`.", mailformBaseURLEnvVar, mailform.DefaultBaseURL)
+
+	// auditLogPathDescription is shared by both provider schemas in the same way
+	auditLogPathDescription = "Path of a file to append a JSON Lines record to whenever an order is created, changes state or is deleted. Records hold the time, order ID, customer reference, recipient, service, total and checksum of the PDF, so that orders can be accounted for after they are removed from state. The file is created if needed and readable by its owner only."
+
+	// cassettes are the cassettes opened by this process, keyed by path
+	cassettes   = map[string]*cassette.Transport{}
+	cassettesMu sync.Mutex
 )
 
 func init() {
@@ -37,9 +66,22 @@ func New(version string) func() *schema.Provider {
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc(mailformTokenAPIEnvVar, nil),
 				},
+				"audit_log_path": {
+					Description: auditLogPathDescription,
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+				"base_url": {
+					Description:  baseURLDescription,
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc(mailformBaseURLEnvVar, mailform.DefaultBaseURL),
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
 				"mailform_order": dataSourceOrder(),
+				"mailform_pdf":   dataSourcePDF(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
 				"mailform_order": resourceMailformOrder(),
@@ -53,16 +95,95 @@ func New(version string) func() *schema.Provider {
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	var diags diag.Diagnostics
+	providerConfig, err := newProviderConfig(ctx, d.Get("api_token").(string), d.Get("base_url").(string), d.Get("audit_log_path").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return providerConfig, nil
+}
+
+// newProviderConfig creates the API client shared by the resources and data sources of both providers
+func newProviderConfig(ctx context.Context, apiToken, baseURL, auditLogPath string) (providerConfig map[string]interface{}, err error) {
+	ctx, span := startSpan(ctx, spanConfigure)
+	defer func() { endSpan(span, err) }()
 
-	api_token := d.Get("api_token").(string)
-	client, err := mailform.New(&mailform.Config{
-		Token: api_token,
+	ctx = newLogContext(ctx, apiToken)
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if path := os.Getenv(mailformCassetteEnvVar); path != "" {
+		transport, err = openCassette(path, apiToken)
+		if err != nil {
+			return nil, err
+		}
+		tflog.Info(ctx, fmt.Sprintf("using cassette %s", path))
+	}
+
+	// Each request is logged and, with a cassette, recorded or replayed
+	logger := newRequestLogger(ctx, transport)
+	client, err := mailformapi.New(&mailformapi.Config{
+		Token:     apiToken,
+		BaseURL:   baseURL,
+		Transport: logger,
 	})
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, err
 	}
-	providerConfig := make(map[string]interface{})
+	providerConfig = make(map[string]interface{})
 	providerConfig["client"] = client
-	return providerConfig, diags
+	providerConfig["api_token"] = apiToken
+	providerConfig["request_logger"] = logger
+	if auditLogPath != "" {
+		providerConfig["audit_log"] = newAuditLog(auditLogPath)
+	}
+	return providerConfig, nil
+}
+
+// NewMuxServer serves the SDKv2 and framework providers as one protocol 6 provider, so that provider
+// functions, which SDKv2 cannot serve, are offered alongside the SDKv2 resources and data sources.
+//
+// The framework provider serves functions only. No resource or data source has been ported to it;
+// one would move by removing the type from the SDKv2 maps in New and returning it from the framework
+// provider with the same schema and schema version, and would also need the framework provider to
+// configure a client.
+func NewMuxServer(ctx context.Context, version string) (func() tfprotov6.ProviderServer, error) {
+	upgradedSDKServer, err := tf5to6server.UpgradeServer(ctx, New(version)().GRPCProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := []func() tfprotov6.ProviderServer{
+		func() tfprotov6.ProviderServer {
+			return upgradedSDKServer
+		},
+		providerserver.NewProtocol6(NewFramework(version)()),
+	}
+	muxServer, err := tf6muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+// openCassette returns the transport recording or replaying the cassette at path
+func openCassette(path, token string) (*cassette.Transport, error) {
+	// Providers configured more than once in a process, such as by acceptance tests, share a recording
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+	if transport, ok := cassettes[path]; ok {
+		return transport, nil
+	}
+
+	mode := os.Getenv(mailformCassetteModeEnvVar)
+	if mode == "" {
+		mode = cassette.ModeReplay
+	}
+
+	transport, err := cassette.New(path, mode, nil, token)
+	if err != nil {
+		return nil, err
+	}
+
+	cassettes[path] = transport
+	return transport, nil
 }
@@ -1,28 +1,187 @@
 package provider
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformtest"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-// providerFactories are used to instantiate a provider during acceptance testing.
+const testAccAPIToken = "test-token"
+
+// testAccServer is a fake Mailform API that acceptance tests order from
+var testAccServer *mailformtest.Server
+
+// protoV6ProviderFactories are used to instantiate a provider during acceptance testing.
 // The factory function will be invoked for every Terraform CLI command executed
 // to create a provider server to which the CLI can reattach.
-var providerFactories = map[string]func() (*schema.Provider, error){
-	"mailform": func() (*schema.Provider, error) {
-		return New("dev")(), nil
+// Providers are configured to use testAccServer so that orders are never sent to Mailform.
+var protoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"mailform": func() (tfprotov6.ProviderServer, error) {
+		os.Setenv(mailformTokenAPIEnvVar, testAccAPIToken)
+		os.Setenv(mailformBaseURLEnvVar, testAccServer.URL)
+		muxServer, err := NewMuxServer(context.Background(), "dev")
+		if err != nil {
+			return nil, err
+		}
+		return muxServer(), nil
 	},
 }
 
+func TestMain(m *testing.M) {
+	testAccServer = mailformtest.NewServer(testAccAPIToken)
+	code := m.Run()
+	testAccServer.Close()
+	os.Exit(code)
+}
+
 func TestProvider(t *testing.T) {
 	if err := New("dev")().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 }
 
+// TestMuxServer checks that the SDKv2 and framework provider schemas match and that every type is
+// served by exactly one of them
+func TestMuxServer(t *testing.T) {
+	ctx := context.Background()
+	muxServer, err := NewMuxServer(ctx, "dev")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resp, err := muxServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+	for _, name := range []string{"mailform_order", "mailform_pdf"} {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("expected resource %s", name)
+		}
+		if _, ok := resp.DataSourceSchemas[name]; !ok {
+			t.Errorf("expected data source %s", name)
+		}
+	}
+	for _, name := range []string{"format_address", "validate_postcode"} {
+		if _, ok := resp.Functions[name]; !ok {
+			t.Errorf("expected function %s", name)
+		}
+	}
+}
+
+// TestFrameworkProviderConfigure checks that the framework provider, which serves only functions, builds no client
+func TestFrameworkProviderConfigure(t *testing.T) {
+	ctx := context.Background()
+	p := NewFramework("dev")()
+	schemaResp := &fwprovider.SchemaResponse{}
+	p.Schema(ctx, fwprovider.SchemaRequest{}, schemaResp)
+
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+			"api_token":      tftypes.NewValue(tftypes.String, testAccAPIToken),
+			"audit_log_path": tftypes.NewValue(tftypes.String, filepath.Join(t.TempDir(), "orders.jsonl")),
+			"base_url":       tftypes.NewValue(tftypes.String, testAccServer.URL),
+		}),
+	}
+	resp := &fwprovider.ConfigureResponse{}
+	p.Configure(ctx, fwprovider.ConfigureRequest{Config: config}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("err: %v", resp.Diagnostics)
+	}
+	if resp.ResourceData != nil || resp.DataSourceData != nil {
+		t.Errorf("expected no provider data, got %v", resp.ResourceData)
+	}
+}
+
+// testRunFunction calls a provider-defined function as Terraform would, returning its result
+func testRunFunction(t *testing.T, f function.Function, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+
+	ctx := context.Background()
+	definition := &function.DefinitionResponse{}
+	f.Definition(ctx, function.DefinitionRequest{}, definition)
+	if definition.Diagnostics.HasError() {
+		t.Fatalf("err: %v", definition.Diagnostics)
+	}
+
+	result, err := definition.Definition.Return.NewResultData(ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp := &function.RunResponse{Result: result}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, resp)
+	return resp.Result.Value(), resp.Error
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+// order.json was recorded against the fake API in internal/mailformtest, not against Mailform
+func TestProviderConfigureCassette(t *testing.T) {
+	t.Setenv(mailformCassetteEnvVar, filepath.Join("testdata", "cassettes", "order.json"))
+
+	// Nothing listens on the base URL, so every response is replayed from the cassette
+	d := schema.TestResourceDataRaw(t, New("dev")().Schema, map[string]any{
+		"api_token": "replayed",
+		"base_url":  "http://127.0.0.1:1",
+	})
+	meta, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	order := schema.TestResourceDataRaw(t, dataSourceOrder().Schema, map[string]any{"id": "ord_000001"})
+	if diags := orderRead(context.Background(), order, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if state := order.Get("state").(string); state != mailform.StatusQueued {
+		t.Errorf("expected %s, got %s", mailform.StatusQueued, state)
+	}
+	if name := order.Get("lineitems.0.to_name").(string); name != "REDACTED" {
+		t.Errorf("expected the recipient to be scrubbed, got %q", name)
+	}
+
+	missing := schema.TestResourceDataRaw(t, dataSourceOrder().Schema, map[string]any{"id": "ord_missing"})
+	if diags := orderRead(context.Background(), missing, meta); diags.HasError() || missing.Id() != "" {
+		t.Errorf("expected a missing order to be removed, got %v", diags)
+	}
+}
+
+func TestAccResourceOrderCassette(t *testing.T) {
+	t.Setenv(mailformCassetteEnvVar, filepath.Join("testdata", "cassettes", "order.json"))
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceOrder,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mailform_order.example", "id", "ord_000001"),
+					resource.TestCheckResourceAttr("mailform_order.example", "customer_reference", "invoice-42"),
+					resource.TestCheckResourceAttr("data.mailform_order.example", "state", mailform.StatusQueued),
+				),
+			},
+		},
+	})
+}
@@ -2,41 +2,63 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
 )
 
 const (
-	orderStatusPollInterval        = time.Minute * 30
 	orderFulFillmentDefaultTimeout = time.Hour * 24 * 5 // 5 days
 )
 
+const (
+	deletionPolicyForget = "forget"
+	deletionPolicyError  = "error"
+)
+
 var (
+	deletionPolicies = []string{deletionPolicyForget, deletionPolicyError}
+
 	errOrderCancelled = errors.New("order has been cancelled")
+	errWaitTimedOut   = errors.New("waiting for order to be fulfilled timed out")
+
+	// orderStatusPollInterval is how often orders are read while waiting for fulfillment, shortened by tests
+	orderStatusPollInterval = time.Minute * 30
 )
 
 var orderInputSchema = map[string]*schema.Schema{
 	"pdf_file": {
-		Description:   "File path of PDF to be printed and mailed by mailform. Orders cannot be updated/deleted.",
+		Description:   "File path of PDF to be printed and mailed by mailform. Orders cannot be updated. Local PDFs are preflighted before ordering: exceeding the page limit of the service fails the order, while page sizes other than the service prints, content within a quarter inch of the page edge and fonts that are not embedded are reported as warnings.",
 		Type:          schema.TypeString,
 		Optional:      true,
-		ConflictsWith: []string{"pdf_url"},
+		ConflictsWith: []string{"pdf_url", "pdf_content_base64"},
 		ForceNew:      true,
 	},
 	"pdf_url": {
 		Description:   "URL of PDF to be printed and mailed by mailform.",
 		Type:          schema.TypeString,
 		Optional:      true,
-		ConflictsWith: []string{"pdf_file"},
+		ConflictsWith: []string{"pdf_file", "pdf_content_base64"},
+		ForceNew:      true,
+	},
+	"pdf_content_base64": {
+		Description:   "Base64 encoded content of the PDF to be printed and mailed by mailform, such as `content_base64` of a `mailform_pdf` data source. Nothing needs to persist on local disk between plan and apply.",
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"pdf_file", "pdf_url"},
+		ValidateFunc:  validation.StringIsBase64,
 		ForceNew:      true,
 	},
 	"customer_reference": {
@@ -221,6 +243,13 @@ var orderInputSchema = map[string]*schema.Schema{
 		Optional:    true,
 		ForceNew:    true,
 	},
+	"deletion_policy": {
+		Description:  fmt.Sprintf("What happens to the order when the resource is destroyed. `%s` removes it from state only, leaving it to be mailed. `%s` fails to destroy the order, so it must be removed from state explicitly. Defaults to `%s`.", deletionPolicyForget, deletionPolicyError, deletionPolicyForget),
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      deletionPolicyForget,
+		ValidateFunc: validation.StringInSlice(deletionPolicies, false),
+	},
 	"wait_until_fulfilled": {
 		Description: "Wait until order is fulfilled (mailed). Default timeout is 5 days, but may be overridden using a timeouts block.",
 		Type:        schema.TypeBool,
@@ -232,6 +261,11 @@ var orderInputSchema = map[string]*schema.Schema{
 		Type:     schema.TypeString,
 		Computed: true,
 	},
+	"pdf_sha256": {
+		Description: "SHA-256 checksum of the PDF uploaded with the order. Empty for orders using `pdf_url`.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	},
 }
 
 // getOrderCreateSchema merges the input fields for the mailform_order resource and computed fields for a mailform_order data source
@@ -248,17 +282,55 @@ func resourceMailformOrder() *schema.Resource {
 		Description:   "Mailform order",
 		CreateContext: resourceMailformOrderCreate,
 		ReadContext:   orderRead,
+		UpdateContext: resourceMailformOrderUpdate,
 		DeleteContext: resourceMailformOrderDelete,
 		Schema:        getOrderCreateSchema(),
+		// Version 1 added deletion_policy
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceMailformOrderV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMailformOrderStateUpgradeV0,
+			},
+		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(orderFulFillmentDefaultTimeout),
 		},
 	}
 }
 
+// resourceMailformOrderV0 is the schema of orders placed before deletion_policy was added
+func resourceMailformOrderV0() *schema.Resource {
+	s := getOrderCreateSchema()
+	delete(s, "deletion_policy")
+	return &schema.Resource{Schema: s}
+}
+
+// resourceMailformOrderStateUpgradeV0 sets the default deletion_policy of orders placed before it was added,
+// so that they show no difference from configurations that leave it unset
+func resourceMailformOrderStateUpgradeV0(ctx context.Context, rawState map[string]any, meta any) (map[string]any, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+	if policy, _ := rawState["deletion_policy"].(string); policy == "" {
+		rawState["deletion_policy"] = deletionPolicyForget
+	}
+	return rawState, nil
+}
+
 func resourceMailformOrderCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	ctx, span := startSpan(ctx, spanOrderCreate, attrService.String(d.Get("service").(string)))
+	diags := createOrder(ctx, d, m)
+	span.SetAttributes(attrOrderID.String(d.Id()))
+	endSpan(span, diagnosticsError(diags))
+	return diags
+}
+
+// createOrder places an order, waiting until it is fulfilled if wait_until_fulfilled is set
+func createOrder(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	providerConfig := m.(map[string]interface{})
-	client := providerConfig["client"].(*mailform.Client)
+	client := providerConfig["client"].(*mailformapi.Client)
 	order := mailform.OrderInput{
 		FilePath:          d.Get("pdf_file").(string),
 		URL:               d.Get("pdf_url").(string),
@@ -294,67 +366,205 @@ func resourceMailformOrderCreate(ctx context.Context, d *schema.ResourceData, m
 		CheckMemo:         d.Get("check_memo").(string),
 	}
 
-	result, err := client.CreateOrder(order)
+	if content := d.Get("pdf_content_base64").(string); content != "" {
+		// The client uploads from disk, so the content only touches disk for the duration of the upload
+		filePath, err := writeOrderContent(content)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer os.Remove(filePath)
+		order.FilePath = filePath
+	}
+
+	ctx = orderLogContext(ctx, m, order)
+	diags := checkOrderPDF(order.FilePath, order.Service)
+	if diags.HasError() {
+		return diags
+	}
+
+	if order.FilePath != "" {
+		checksum, err := fileSHA256(order.FilePath)
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		if err := d.Set("pdf_sha256", checksum); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+
+	tflog.SubsystemDebug(ctx, logSubsystem, "creating order")
+	start := time.Now()
+	result, err := uploadOrder(ctx, client, order)
 	if err != nil {
+		tflog.SubsystemError(ctx, logSubsystem, "creating order failed", map[string]any{
+			logKeyLatency: time.Since(start).Milliseconds(),
+			logKeyError:   err.Error(),
+		})
 		return diag.FromErr(err)
 	}
 
 	orderID := result.Data.ID
 	d.SetId(orderID)
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, logKeyOrderID, orderID)
+	tflog.SubsystemInfo(ctx, logSubsystem, "created order", map[string]any{
+		logKeyLatency:   time.Since(start).Milliseconds(),
+		logKeyRequestID: lastRequestID(m, orderID),
+	})
+	diags = append(diags, auditCreatedOrder(d, m, result)...)
+	// Changes of state while waiting are audited by readOrder
+	if err := d.Set("state", result.Data.State); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
 
 	if d.Get("wait_until_fulfilled").(bool) {
-		ticker := time.NewTicker(orderStatusPollInterval)
-		timer := time.NewTimer(d.Timeout(schema.TimeoutCreate))
-
-		// If order was cancelled, break early
-		order, err := client.GetOrder(orderID)
+		err := waitForFulfillment(ctx, m, client, orderID, d.Timeout(schema.TimeoutCreate))
 		if err != nil {
-			tflog.Error(ctx, err.Error())
+			return append(diags, diag.FromErr(err)...)
 		}
-		orderStatus := order.Data.State
-		if orderStatus == mailform.StatusCancelled {
-			return diag.FromErr(errOrderCancelled)
+	}
+
+	// Set computed fields in state. Saves alot of copy paste by just running an extra GET after creating the order
+	return append(diags, readOrder(ctx, d, m)...)
+}
+
+// uploadOrder sends an order along with its PDF, unless Mailform fetches the PDF from pdf_url
+func uploadOrder(ctx context.Context, client *mailformapi.Client, order mailform.OrderInput) (*mailform.Order, error) {
+	attributes := []attribute.KeyValue{attrPDFSource.String("url")}
+	if order.FilePath != "" {
+		attributes = []attribute.KeyValue{attrPDFSource.String("file")}
+		if info, err := os.Stat(order.FilePath); err == nil {
+			attributes = append(attributes, attrPDFBytes.Int64(info.Size()))
 		}
+	}
+
+	_, span := startSpan(ctx, spanPDFUpload, attributes...)
+	result, err := client.CreateOrder(order)
+	if err == nil {
+		span.SetAttributes(attrOrderID.String(result.Data.ID))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// waitForFulfillment polls an order until it has been mailed. It returns early if the order is cancelled
+// and once timeout has passed, which is checked between polls rather than after them.
+func waitForFulfillment(ctx context.Context, m any, client *mailformapi.Client, orderID string, timeout time.Duration) error {
+	ticker := time.NewTicker(orderStatusPollInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-		for {
-			select {
-			case <-ticker.C:
-				// Get order status
-				order, err := client.GetOrder(orderID)
-				if err != nil {
-					tflog.Error(ctx, err.Error())
-				}
-				orderStatus := order.Data.State
-				tflog.Debug(ctx, fmt.Sprintf("order state: %s", orderStatus))
-
-				// If order was cancelled, break
-				if orderStatus == mailform.StatusCancelled {
-					return diag.FromErr(errOrderCancelled)
-				}
-
-				// If order has been mailed
-				if orderStatus == mailform.StatusFulfilled {
-					break
-				}
-				select {
-				case <-timer.C:
-					return diag.FromErr(errors.New("waiting for order to be fulfilled timed out"))
-				default:
-					break
-				}
-			case <-ctx.Done():
-				return diag.FromErr(errors.New("waiting for order cancelled"))
-			}
+	for {
+		switch pollOrderState(ctx, m, client, orderID) {
+		case mailform.StatusCancelled:
+			return errOrderCancelled
+		case mailform.StatusFulfilled:
+			return nil
 		}
 
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return errWaitTimedOut
+		case <-ctx.Done():
+			return errors.New("waiting for order cancelled")
+		}
 	}
+}
 
-	// Set computed fields in state. Saves alot of copy paste by just running an extra GET after creating the order
-	return orderRead(ctx, d, m)
+// pollOrderState reads the state of an order while waiting for it to be fulfilled. Errors are
+// logged and the order polled again.
+func pollOrderState(ctx context.Context, m any, client *mailformapi.Client, orderID string) string {
+	_, span := startSpan(ctx, spanOrderPoll, attrOrderID.String(orderID))
+	start := time.Now()
+	order, err := client.GetOrder(orderID)
+	span.SetAttributes(attrOrderState.String(order.Data.State))
+	endSpan(span, err)
+	fields := map[string]any{
+		logKeyLatency:   time.Since(start).Milliseconds(),
+		logKeyRequestID: lastRequestID(m, orderID),
+	}
+	if err != nil {
+		fields[logKeyError] = err.Error()
+		tflog.SubsystemError(ctx, logSubsystem, "polling order failed", fields)
+		return ""
+	}
+
+	fields[logKeyState] = order.Data.State
+	tflog.SubsystemDebug(ctx, logSubsystem, "polled order", fields)
+	return order.Data.State
+}
+
+// checkOrderPDF preflights local PDFs, warning about PDFs that are likely to fail at the printer
+// and returning errors for PDFs the service rejects
+func checkOrderPDF(filePath, service string) diag.Diagnostics {
+	if filePath == "" {
+		return nil
+	}
+
+	// Unreadable files are reported when the order is uploaded
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	diags := preflightPDF(content, service)
+	if isEncryptedPDF(content) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "PDF is encrypted",
+			Detail:   "Mailform may be unable to print an encrypted PDF that requires a password to open or does not permit printing. Remove user_password from the mailform_pdf or allow the print permission.",
+		})
+	}
+
+	return diags
+}
+
+// writeOrderContent decodes base64 encoded PDF content to a temporary file for upload
+func writeOrderContent(content string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode pdf_content_base64: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "mailform-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.Write(decoded)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// resourceMailformOrderUpdate changes deletion_policy, the only attribute that does not replace the order
+func resourceMailformOrderUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	return orderRead(ctx, d, meta)
 }
 
 func resourceMailformOrderDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	// API doesn't support deleting orders, we simply just remove from state
+	var diags diag.Diagnostics
+
+	switch d.Get("deletion_policy").(string) {
+	case deletionPolicyError:
+		return diag.Errorf("order %s has been placed and is not deleted while deletion_policy is %q. Set deletion_policy to %q, or remove the order from state with terraform state rm.", d.Id(), deletionPolicyError, deletionPolicyForget)
+	default:
+		// The API doesn't delete orders, so they are only removed from state
+		if state := d.Get("state").(string); state != mailform.StatusCancelled {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Order removed from state only",
+				Detail:   fmt.Sprintf("Order %s was %s and has not been cancelled, so it has been or will be mailed.", d.Id(), state),
+			})
+		}
+	}
+
+	diags = append(diags, auditOrder(d, meta, auditEventDelete, "")...)
 	d.SetId("")
-	return nil
+	return diags
 }
@@ -0,0 +1,462 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/circa10a/terraform-provider-mailform/internal/mailformapi"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestWriteOrderContent(t *testing.T) {
+	content := []byte("%PDF-1.3")
+
+	filePath, err := writeOrderContent(base64.StdEncoding.EncodeToString(content))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(filePath)
+
+	written, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(content, written) {
+		t.Errorf("expected %q, got %q", content, written)
+	}
+
+	if _, err := writeOrderContent("not base64!"); err == nil {
+		t.Error("expected invalid base64 to fail")
+	}
+}
+
+func TestCheckOrderPDF(t *testing.T) {
+	opts := pdfOptions{
+		header:       "Header",
+		font:         pdfFont{family: defaultFontFamily},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		creationDate: defaultCreationDate,
+		protection:   pdfProtection{ownerPassword: "owner", userPassword: "user"},
+	}
+	content, err := renderPDFContent(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	filePath, err := writeOrderContent(base64.StdEncoding.EncodeToString(content))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(filePath)
+
+	diags := checkOrderPDF(filePath, "USPS_FIRST_CLASS")
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Errorf("expected a warning for an encrypted PDF, got %v", diags)
+	}
+
+	if diags := checkOrderPDF("", "USPS_FIRST_CLASS"); len(diags) != 0 {
+		t.Errorf("expected no warnings without a file, got %v", diags)
+	}
+}
+
+func TestAccResourceOrder(t *testing.T) {
+	var orderID string
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceOrder,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mailform_order.example", "state", mailform.StatusQueued),
+					resource.TestCheckResourceAttr("mailform_order.example", "customer_reference", "invoice-42"),
+					resource.TestCheckResourceAttr("mailform_order.example", "lineitems.0.pagecount", "1"),
+					resource.TestCheckResourceAttr("mailform_order.example", "lineitems.0.service", "USPS_FIRST_CLASS"),
+					resource.TestCheckResourceAttr("mailform_order.example", "lineitems.0.to_name", "A name"),
+					resource.TestCheckResourceAttrSet("mailform_order.example", "total"),
+					testAccCheckOrderUploaded("mailform_order.example", &orderID),
+				),
+			},
+			{
+				// Mailform cancels orders it cannot fulfill, which is picked up on refresh
+				PreConfig: func() {
+					if err := testAccServer.Cancel(orderID, "address_undeliverable"); err != nil {
+						t.Fatalf("err: %s", err)
+					}
+				},
+				Config: testAccResourceOrder,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mailform_order.example", "state", mailform.StatusCancelled),
+					resource.TestCheckResourceAttr("mailform_order.example", "cancellation_reason", "address_undeliverable"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceOrderWaitUntilFulfilled(t *testing.T) {
+	interval := orderStatusPollInterval
+	orderStatusPollInterval = 10 * time.Millisecond
+	testAccServer.AdvanceOnRead = true
+	defer func() {
+		orderStatusPollInterval = interval
+		testAccServer.AdvanceOnRead = false
+	}()
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceOrderWaitUntilFulfilled,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mailform_order.example", "state", mailform.StatusFulfilled),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceOrderError(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig:   func() { testAccServer.FailNext(http.StatusPaymentRequired, "erroroccurred", "Error: Not enough funds") },
+				Config:      testAccResourceOrder,
+				ExpectError: regexp.MustCompile("Not enough funds"),
+			},
+		},
+	})
+}
+
+// testAccCheckOrderUploaded ensures the fake API received the PDF of an order, storing the order's ID
+func testAccCheckOrderUploaded(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found", name)
+		}
+		*id = rs.Primary.ID
+
+		if !bytes.HasPrefix(testAccServer.Upload(*id), []byte("%PDF-")) {
+			return fmt.Errorf("order %s was not uploaded with a PDF", *id)
+		}
+		return nil
+	}
+}
+
+const testAccOrderAddresses = `
+  service        = "USPS_FIRST_CLASS"
+  to_name        = "A name"
+  to_address_1   = "Address 1"
+  to_city        = "Seattle"
+  to_state       = "WA"
+  to_postcode    = "00000"
+  to_country     = "US"
+  from_name      = "My name"
+  from_address_1 = "My Address 1"
+  from_city      = "Dallas"
+  from_state     = "TX"
+  from_postcode  = "00000"
+  from_country   = "US"
+`
+
+const testAccResourceOrder = `
+data "mailform_pdf" "example" {
+  header  = "Invoice"
+  content = "Amount due: $42"
+}
+
+resource "mailform_order" "example" {
+  pdf_content_base64 = data.mailform_pdf.example.content_base64
+  customer_reference = "invoice-42"
+` + testAccOrderAddresses + `
+}
+`
+
+const testAccResourceOrderWaitUntilFulfilled = `
+resource "mailform_order" "example" {
+  pdf_url              = "https://example.com/letter.pdf"
+  wait_until_fulfilled = true
+` + testAccOrderAddresses + `
+}
+`
+
+func TestResourceMailformOrderCreate(t *testing.T) {
+	interval := orderStatusPollInterval
+	orderStatusPollInterval = 10 * time.Millisecond
+	testAccServer.AdvanceOnRead = true
+	defer func() {
+		orderStatusPollInterval = interval
+		testAccServer.AdvanceOnRead = false
+	}()
+
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	meta, err := newProviderConfig(ctx, testAccAPIToken, testAccServer.URL, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{
+		"pdf_url":              "https://example.com/letter.pdf",
+		"wait_until_fulfilled": true,
+		"service":              "USPS_FIRST_CLASS",
+		"to_name":              "A name",
+		"to_address_1":         "Address 1",
+		"to_city":              "Seattle",
+		"to_state":             "WA",
+		"to_postcode":          "00000",
+		"to_country":           "US",
+		"from_name":            "My name",
+		"from_address_1":       "My Address 1",
+		"from_city":            "Dallas",
+		"from_state":           "TX",
+		"from_postcode":        "00000",
+		"from_country":         "US",
+	})
+
+	diags := resourceMailformOrderCreate(ctx, d, meta)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if state := d.Get("state").(string); state != mailform.StatusFulfilled {
+		t.Errorf("expected %s, got %s", mailform.StatusFulfilled, state)
+	}
+	if to := d.Get("lineitems.0.to_formatted").(string); to == "" {
+		t.Error("expected line items to be read after creating the order")
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	requests, polls := 0, 0
+	for _, entry := range entries {
+		line := fmt.Sprint(entry)
+		for _, secret := range []string{testAccAPIToken, "A name", "My Address 1"} {
+			if strings.Contains(line, secret) {
+				t.Errorf("expected %q to be masked, got %s", secret, line)
+			}
+		}
+
+		switch entry["@message"] {
+		case "Mailform API request":
+			requests++
+			for _, key := range []string{logKeyMethod, logKeyPath, logKeyStatus, logKeyLatency, logKeyRequestID, logKeyOrderID} {
+				if _, ok := entry[key]; !ok {
+					t.Errorf("expected %s in %v", key, entry)
+				}
+			}
+		case "created order", "polled order", "read order":
+			if entry["@message"] == "polled order" {
+				polls++
+			}
+			if entry[logKeyOrderID] != d.Id() || entry[logKeyService] != "USPS_FIRST_CLASS" || entry[logKeyRequestID] == "" {
+				t.Errorf("expected order, service and request ID fields, got %v", entry)
+			}
+		}
+	}
+	// Created, polled until fulfilled, then read
+	if requests != polls+2 || polls == 0 {
+		t.Errorf("expected every request to be logged, got %d requests and %d polls", requests, polls)
+	}
+}
+
+func TestWaitForFulfillment(t *testing.T) {
+	interval := orderStatusPollInterval
+	orderStatusPollInterval = 10 * time.Millisecond
+	defer func() {
+		orderStatusPollInterval = interval
+		testAccServer.AdvanceOnRead = false
+	}()
+
+	meta, err := newProviderConfig(context.Background(), testAccAPIToken, testAccServer.URL, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client := meta["client"].(*mailformapi.Client)
+
+	tests := []struct {
+		name          string
+		state         string
+		advanceOnRead bool
+		timeout       time.Duration
+		expected      error
+	}{
+		// The loop must exit once the order is mailed rather than polling until the timeout
+		{name: "fulfilled", state: mailform.StatusQueued, advanceOnRead: true, timeout: time.Minute},
+		{name: "already fulfilled", state: mailform.StatusFulfilled, timeout: time.Minute},
+		{name: "cancelled", state: mailform.StatusCancelled, timeout: time.Minute, expected: errOrderCancelled},
+		{name: "timed out", state: mailform.StatusQueued, timeout: 50 * time.Millisecond, expected: errWaitTimedOut},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id := testOrder(t, test.state)
+			testAccServer.AdvanceOnRead = test.advanceOnRead
+
+			start := time.Now()
+			err := waitForFulfillment(context.Background(), meta, client, id, test.timeout)
+			if err != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("expected waiting to stop promptly, took %s", elapsed)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	testAccServer.AdvanceOnRead = false
+	if err := waitForFulfillment(ctx, meta, client, testOrder(t, mailform.StatusQueued), time.Minute); err == nil {
+		t.Error("expected waiting to stop when the context is cancelled")
+	}
+}
+
+func TestResourceMailformOrderStateUpgradeV0(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    map[string]any
+		expected string
+	}{
+		// Orders placed before deletion_policy was added must match its default, or every one shows a difference
+		{name: "unset", state: map[string]any{"id": "ord_000001", "service": "USPS_FIRST_CLASS"}, expected: deletionPolicyForget},
+		{name: "set", state: map[string]any{"id": "ord_000001", "deletion_policy": deletionPolicyError}, expected: deletionPolicyError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upgraded, err := resourceMailformOrderStateUpgradeV0(context.Background(), test.state, nil)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if upgraded["deletion_policy"] != test.expected {
+				t.Errorf("expected %q, got %v", test.expected, upgraded["deletion_policy"])
+			}
+		})
+	}
+
+	if policy := resourceMailformOrder().Schema["deletion_policy"].Default; policy != deletionPolicyForget {
+		t.Errorf("expected the default deletion_policy to be %q as set by the upgrade, got %v", deletionPolicyForget, policy)
+	}
+	if _, ok := resourceMailformOrderV0().Schema["deletion_policy"]; ok {
+		t.Error("expected version 0 to have no deletion_policy")
+	}
+}
+
+// testOrder places an order with the fake API in the given state and returns its ID
+func testOrder(t *testing.T, state string) string {
+	t.Helper()
+
+	client, err := mailformapi.New(&mailformapi.Config{Token: testAccAPIToken, BaseURL: testAccServer.URL})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "order.pdf")
+	if err := os.WriteFile(filePath, []byte("%PDF-1.3\n1 0 obj\n<</Type /Page>>\nendobj\n"), 0o600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	order, err := client.CreateOrder(mailform.OrderInput{
+		FilePath:     filePath,
+		Service:      "USPS_FIRST_CLASS",
+		ToName:       "Jane Doe",
+		ToAddress1:   "1 Main St",
+		ToCity:       "Seattle",
+		ToState:      "WA",
+		ToPostcode:   "98101",
+		ToCountry:    "US",
+		FromName:     "John Doe",
+		FromAddress1: "2 Main St",
+		FromCity:     "Dallas",
+		FromState:    "TX",
+		FromPostcode: "75001",
+		FromCountry:  "US",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	id := order.Data.ID
+	switch state {
+	case mailform.StatusQueued:
+	case mailform.StatusCancelled:
+		if err := testAccServer.Cancel(id, "duplicate"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	default:
+		if err := testAccServer.SetState(id, state); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	return id
+}
+
+func TestResourceMailformOrderDelete(t *testing.T) {
+	meta, err := newProviderConfig(context.Background(), testAccAPIToken, testAccServer.URL, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		state    string
+		severity []diag.Severity
+		removed  bool
+		// expected is the state of the order with the API afterwards
+		expected string
+	}{
+		{name: "forget", policy: deletionPolicyForget, state: mailform.StatusQueued, severity: []diag.Severity{diag.Warning}, removed: true, expected: mailform.StatusQueued},
+		{name: "forget cancelled", policy: deletionPolicyForget, state: mailform.StatusCancelled, removed: true, expected: mailform.StatusCancelled},
+		{name: "error", policy: deletionPolicyError, state: mailform.StatusQueued, severity: []diag.Severity{diag.Error}, expected: mailform.StatusQueued},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id := testOrder(t, test.state)
+			d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{
+				"service":         "USPS_FIRST_CLASS",
+				"deletion_policy": test.policy,
+			})
+			d.SetId(id)
+			if diags := orderRead(context.Background(), d, meta); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+
+			diags := resourceMailformOrderDelete(context.Background(), d, meta)
+			if len(diags) != len(test.severity) {
+				t.Fatalf("expected %d diagnostics, got %v", len(test.severity), diags)
+			}
+			for i, severity := range test.severity {
+				if diags[i].Severity != severity {
+					t.Errorf("expected severity %v, got %v", severity, diags[i])
+				}
+			}
+			if removed := d.Id() == ""; removed != test.removed {
+				t.Errorf("expected removed from state to be %t", test.removed)
+			}
+
+			order, _ := testAccServer.Order(id)
+			if order.State != test.expected {
+				t.Errorf("expected order to be %s, got %s", test.expected, order.State)
+			}
+		})
+	}
+}
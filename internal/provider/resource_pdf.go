@@ -1,32 +1,48 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
-	"errors"
+	"fmt"
 	"io/ioutil"
-	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	// defaultCreationDate is embedded in PDFs without a creation_date so that output is reproducible
+	defaultCreationDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+const (
+	// pdfFileMode is the permission of rendered PDFs, matching files created by os.Create under a typical umask
+	pdfFileMode = 0o644
 )
 
 func resourcePDF() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		// This description is used by the documentation generator and the language server.
 		Description: "Render a PDF and write to a local file.",
 
 		CreateContext: resourcePDFCreate,
 		ReadContext:   resourcePDFRead,
+		UpdateContext: resourcePDFUpdate,
 		DeleteContext: resourcePDFDelete,
+		CustomizeDiff: resourcePDFCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"filename": {
-				Description: "The path to the PDF file that will be created",
+				Description: "The path to the PDF file that will be created. Changing any other argument re-renders the file in place.",
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
@@ -35,97 +51,315 @@ func resourcePDF() *schema.Resource {
 				Description: "Header/title of PDF",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				ConflictsWith: []string{
 					"image_filename",
+					"images",
 				},
 			},
 			"content": {
 				Description: "Content of PDF",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				ConflictsWith: []string{
 					"image_filename",
+					"images",
+				},
+			},
+			"font_family": {
+				Description: "Font family used to render the header and content. Either a core font (`Arial`, `Courier`, `Helvetica`, `Times`) or the name to register `font_file` under. Defaults to `Arial`, or the base name of `font_file` if set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ConflictsWith: []string{
+					"image_filename",
+					"images",
+				},
+			},
+			"font_file": {
+				Description: "A UTF-8 TrueType font file to embed. Required to render characters outside of cp1252 such as CJK or Cyrillic.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ConflictsWith: []string{
+					"image_filename",
+					"images",
+				},
+				ValidateFunc: validateFontFile,
+			},
+			"fonts": {
+				Description: "TrueType font files for the bold and italic variants of `font_file`. Text falls back to `font_file` when a variant is not set.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				RequiredWith: []string{
+					"font_file",
+				},
+				Elem: &schema.Resource{
+					Schema: fontsSchema,
+				},
+			},
+			"address_window": {
+				Description: "Print the recipient and return addresses on the first page where the windows of a standard #10 double window envelope expose them. Body content starts beneath the windows, and a `qr_code`, `barcode`, `signature` or letterhead image on the first page must not cover them. Requires a portrait `Letter`, `Legal` or `A4` page.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				ConflictsWith: []string{
+					"image_filename",
+					"images",
+				},
+				Elem: &schema.Resource{
+					Schema: addressWindowSchema(),
+				},
+			},
+			"table": {
+				Description: "A table printed beneath the content. Tables that span multiple pages repeat their header row on each page.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ConflictsWith: []string{
+					"image_filename",
+					"images",
+				},
+				Elem: &schema.Resource{
+					Schema: tableSchema,
+				},
+			},
+			"qr_code": {
+				Description: "A QR code drawn on the PDF, such as a link for responding to a mailer.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: qrCodeSchema(),
+				},
+			},
+			"barcode": {
+				Description: "A barcode drawn on the PDF, such as a tracking number.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: barcodeSchema(),
+				},
+			},
+			"signature": {
+				Description: "A handwritten signature image stamped on the PDF, with the signer's printed name and date beneath it.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				ConflictsWith: []string{
+					"image_filename",
+					"images",
+				},
+				Elem: &schema.Resource{
+					Schema: signatureSchema,
+				},
+			},
+			"digital_signature": {
+				Description: "Digitally sign the finished PDF with a PKCS#7 signature using a local certificate and private key. The signature is dated `creation_date`. PDFs signed with an RSA key are byte-identical between renders, while ECDSA signatures differ, so the `mailform_pdf` data source requires an RSA key.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				ConflictsWith: []string{
+					"owner_password",
+					"user_password",
+					"permissions",
+				},
+				Elem: &schema.Resource{
+					Schema: digitalSignatureSchema,
 				},
 			},
+			"source_file": {
+				Description: fmt.Sprintf("A finished PDF to copy to `filename` instead of rendering one. The file must be an unencrypted PDF with a valid cross-reference table and at most %d pages. Changes to the source file are detected by checksum and cause it to be copied again, while a missing source file is reported as a warning and the copy is kept.", mailformMaxPages),
+				Type:        schema.TypeString,
+				Optional:    true,
+				ConflictsWith: []string{
+					"header",
+					"content",
+					"font_family",
+					"font_file",
+					"fonts",
+					"address_window",
+					"table",
+					"qr_code",
+					"barcode",
+					"signature",
+					"digital_signature",
+					"creation_date",
+					"image_filename",
+					"images",
+					"margins",
+					"page_width",
+					"page_height",
+					"letterhead_image",
+					"header_text",
+					"footer_text",
+					"page_numbers",
+					"owner_password",
+					"user_password",
+					"permissions",
+				},
+				ValidateFunc: validateSourceFile,
+			},
+			"creation_date": {
+				Description:  "The creation date embedded in the PDF as an RFC 3339 timestamp. Defaults to `2000-01-01T00:00:00Z` so that identical inputs always render identical files.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
 			"image_filename": {
 				Description: "The image file to be converted to a PDF. Typically used for postcards",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				ConflictsWith: []string{
 					"header",
 					"content",
+					"font_family",
+					"font_file",
+					"images",
 				},
-				ValidateFunc: func(val any, key string) (warns []string, errs []error) {
-					buf := make([]byte, 512)
-
-					imageFilename := val.(string)
-					file, err := os.Open(imageFilename)
-					if err != nil {
-						errs = append(errs, err)
-						return warns, errs
-					}
+				ValidateFunc: validateImageFile,
+			},
+		},
+	}
 
-					defer file.Close()
+	// Page layout is shared by both text and image PDFs
+	maps.Copy(r.Schema, layoutSchema)
+	maps.Copy(r.Schema, letterheadSchema)
+	maps.Copy(r.Schema, imageSchema)
+	maps.Copy(r.Schema, protectionSchema)
+	maps.Copy(r.Schema, pdfMetadataSchema)
 
-					_, err = file.Read(buf)
-					if err != nil {
-						errs = append(errs, err)
-						return warns, errs
-					}
+	return r
+}
 
-					contentType := http.DetectContentType(buf)
+func resourcePDFCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	diags := writePDFResource(ctx, d)
+	if diags.HasError() {
+		return diags
+	}
 
-					if contentType != "image/png" && contentType != "image/jpeg" {
-						errs = append(errs, errors.New("image file is not a valid image"))
-						return warns, errs
-					}
+	tflog.Trace(ctx, "created a pdf resource")
 
-					return warns, errs
-				},
-			},
-		},
-	}
+	return nil
 }
 
-func resourcePDFCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+func resourcePDFUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	diags := writePDFResource(ctx, d)
+	if diags.HasError() {
+		return diags
+	}
 
-	// Used for generating pdfs and also converting pdf's to images
-	filename := d.Get("filename").(string)
+	tflog.Trace(ctx, "updated a pdf resource")
 
-	// If an image, convert to pdf
-	if imageFilename, ok := d.GetOk("image_filename"); ok {
-		err := convertImage(imageFilename.(string), filename)
-		if err != nil {
-			defer resourcePDFDelete(ctx, d, filename)
-			return diag.FromErr(err)
-		}
-	} else {
-		// Generate content if not image
-		header := d.Get("header").(string)
-		content := d.Get("content").(string)
+	return nil
+}
 
-		err := renderPDF(header, content, filename)
-		if err != nil {
-			return diag.FromErr(err)
-		}
+// writePDFResource renders a mailform_pdf resource to its filename and sets its ID to the checksum of the output
+func writePDFResource(ctx context.Context, d *schema.ResourceData) diag.Diagnostics {
+	content, err := pdfResourceContent(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	outputContent, err := ioutil.ReadFile(filename)
+	err = writeFileAtomic(d.Get("filename").(string), content)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	checksum := sha1.Sum([]byte(outputContent))
+	checksum := sha1.Sum(content)
 	d.SetId(hex.EncodeToString(checksum[:]))
 
-	tflog.Trace(ctx, "created a pdf resource")
+	err = newPDFMetadata(content).set(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	return nil
 }
 
+// pdfResourceContent returns the content of a mailform_pdf, either copied from source_file or rendered from its inputs
+func pdfResourceContent(ctx context.Context, d resourceGetter) (content []byte, err error) {
+	sourceFile := d.Get("source_file").(string)
+	source := "render"
+	if sourceFile != "" {
+		source = "source_file"
+	}
+	_, span := startSpan(ctx, spanPDFRender, attrPDFSource.String(source))
+	defer func() {
+		span.SetAttributes(attrPDFPages.Int(pdfPageCount(content)), attrPDFBytes.Int(len(content)))
+		endSpan(span, err)
+	}()
+
+	if sourceFile != "" {
+		return readSourcePDF(sourceFile)
+	}
+	return renderPDFContent(expandPDFOptions(d))
+}
+
+// writeFileAtomic writes to a temporary file alongside outputFilePath and then renames it into place,
+// so that a failed write never leaves a partially written or missing PDF behind.
+func writeFileAtomic(outputFilePath string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(outputFilePath), "."+filepath.Base(outputFilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Removing the temporary file fails harmlessly once it has been renamed
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	// Temporary files are private, the rendered PDF is not
+	err = os.Chmod(tmpPath, pdfFileMode)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, outputFilePath)
+}
+
+func resourcePDFCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	// Metadata is unknown until an updated PDF has been rendered
+	if d.Id() != "" && len(d.GetChangedKeysPrefix("")) > 0 {
+		for _, key := range pdfMetadataKeys {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Source files are vetted by their ValidateFunc
+	if d.Get("source_file").(string) != "" {
+		return nil
+	}
+
+	// Inputs may not be known until apply, in which case rendering validates them
+	for _, key := range pdfOptionKeys() {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	return expandPDFOptions(d).validate()
+}
+
+// pdfOptionKeys returns the arguments of mailform_pdf read into its pdfOptions, which are all of them
+// other than the output filename and source_file. They are taken from the schema so none are missed.
+func pdfOptionKeys() []string {
+	keys := []string{}
+	for key, s := range resourcePDF().Schema {
+		if key == "filename" || key == "source_file" || !(s.Required || s.Optional) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func resourcePDFRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	// If the output file doesn't exist, mark the resource for creation.
 	outputPath := d.Get("filename").(string)
@@ -148,7 +382,35 @@ func resourcePDFRead(ctx context.Context, d *schema.ResourceData, meta any) diag
 		return nil
 	}
 
-	return nil
+	var diags diag.Diagnostics
+	// A source file that has changed since it was copied must be vetted and copied again. A missing
+	// source file is not a change to the PDF, which is kept until the source is restored or replaced.
+	if sourceFile := d.Get("source_file").(string); sourceFile != "" {
+		sourceContent, err := ioutil.ReadFile(sourceFile)
+		switch {
+		case os.IsNotExist(err):
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "PDF source file not found",
+				Detail:   fmt.Sprintf("The source_file %s no longer exists, so %s could not be checked against it. The copy is kept, but it cannot be replaced until the source file is restored.", sourceFile, outputPath),
+			})
+		case err != nil:
+			return diag.FromErr(err)
+		default:
+			sourceChecksum := sha1.Sum(sourceContent)
+			if hex.EncodeToString(sourceChecksum[:]) != d.Id() {
+				d.SetId("")
+				return nil
+			}
+		}
+	}
+
+	err = newPDFMetadata(outputContent).set(d)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	return diags
 }
 
 func resourcePDFDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
@@ -159,37 +421,256 @@ func resourcePDFDelete(ctx context.Context, d *schema.ResourceData, meta any) di
 	return nil
 }
 
-// renderPDF converts header + content to a pdf and writes to an output file
-func renderPDF(header string, content string, outputFilePath string) error {
-	pdf := gofpdf.New(gofpdf.OrientationPortrait, "mm", gofpdf.PageSizeLetter, "")
+// pdfOptions holds everything needed to render the content of a mailform_pdf resource
+type pdfOptions struct {
+	header     string
+	content    string
+	font       pdfFont
+	layout     pdfLayout
+	letterhead pdfLetterhead
+	// addressWindow is nil unless addresses should be printed for a window envelope
+	addressWindow *pdfAddressWindow
+	tables        []pdfTable
+	barcodes      []pdfBarcode
+	images        pdfImages
+	creationDate  time.Time
+	// signature and digitalSignature are nil unless the PDF should be signed
+	signature        *pdfSignatureStamp
+	digitalSignature *pdfDigitalSignature
+	protection       pdfProtection
+}
+
+// expandPDFOptions reads the rendering configuration of a mailform_pdf resource
+func expandPDFOptions(d resourceGetter) pdfOptions {
+	return pdfOptions{
+		header:        d.Get("header").(string),
+		content:       d.Get("content").(string),
+		font:          expandPDFFont(d),
+		layout:        expandPDFLayout(d),
+		letterhead:    expandPDFLetterhead(d),
+		addressWindow: expandPDFAddressWindow(d),
+		tables:        expandPDFTables(d),
+		barcodes:      expandPDFBarcodes(d),
+		images:        expandPDFImages(d),
+		creationDate:  expandCreationDate(d),
+
+		signature:        expandPDFSignatureStamp(d),
+		digitalSignature: expandPDFDigitalSignature(d),
+		protection:       expandPDFProtection(d),
+	}
+}
+
+// expandCreationDate reads the creation date of a mailform_pdf resource, falling back to a fixed date
+func expandCreationDate(d resourceGetter) time.Time {
+	creationDate, err := time.Parse(time.RFC3339, d.Get("creation_date").(string))
+	if err != nil {
+		return defaultCreationDate
+	}
+	return creationDate.UTC()
+}
+
+// newDocument creates an empty document using the configured page layout.
+// Fixed dates and sorted objects keep the output byte-identical for identical inputs.
+// Encryption is applied before any content is added so that every object is encrypted.
+func (o pdfOptions) newDocument(fallbackMargin float64) *gofpdf.Fpdf {
+	pdf := o.layout.newDocument(fallbackMargin)
+	pdf.SetCreationDate(o.creationDate)
+	pdf.SetModificationDate(o.creationDate)
+	pdf.SetCatalogSort(true)
+	o.protection.apply(pdf)
+	return pdf
+}
+
+// validate ensures the options can be rendered
+func (o pdfOptions) validate() error {
+	if err := o.layout.validate(); err != nil {
+		return err
+	}
+	if err := o.font.validate(); err != nil {
+		return err
+	}
+	if err := o.protection.validate(); err != nil {
+		return err
+	}
+	if err := o.font.validateRunes("B", o.header); err != nil {
+		return err
+	}
+	if err := o.font.validateRunes("", o.content); err != nil {
+		return err
+	}
+	if err := o.font.validateRunes("I", o.letterhead.headerText, o.letterhead.footerText); err != nil {
+		return err
+	}
+	if o.addressWindow != nil {
+		if err := o.addressWindow.validate(o.layout); err != nil {
+			return err
+		}
+		placements, err := o.placements()
+		if err != nil {
+			return err
+		}
+		if err := o.addressWindow.validatePlacements(placements); err != nil {
+			return err
+		}
+		if o.letterhead.image != "" {
+			rect, err := o.letterhead.imageRect(o.layout)
+			if err != nil {
+				return err
+			}
+			if rect.intersects(returnAddressWindow) || rect.intersects(recipientAddressWindow) {
+				return errAddressWindowOverlap
+			}
+		}
+		if err := o.font.validateRunes("", o.addressWindow.text()...); err != nil {
+			return err
+		}
+	}
+	for _, table := range o.tables {
+		if err := table.validate(o.layout); err != nil {
+			return err
+		}
+		if err := o.font.validateRunes("", table.text()...); err != nil {
+			return err
+		}
+		if err := o.font.validateRunes("B", table.boldText()...); err != nil {
+			return err
+		}
+	}
+	for _, code := range o.barcodes {
+		if err := code.validate(o.layout); err != nil {
+			return err
+		}
+	}
+	if o.signature != nil {
+		if err := o.signature.validate(o.layout); err != nil {
+			return err
+		}
+		if err := o.font.validateRunes("", o.signature.text()...); err != nil {
+			return err
+		}
+	}
+	if o.digitalSignature != nil {
+		if _, _, err := o.digitalSignature.load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placements returns the elements printed at a fixed position rather than flowing with the content
+func (o pdfOptions) placements() ([]pdfPlacement, error) {
+	placements := []pdfPlacement{}
+	for _, code := range o.barcodes {
+		placements = append(placements, pdfPlacement{name: fmt.Sprintf("%s %q", code.symbology, code.payload), page: code.page, rect: code.rect})
+	}
+	if o.signature != nil {
+		rect, err := o.signature.imageRect()
+		if err != nil {
+			return nil, err
+		}
+		// The name and date are printed beneath the image
+		rect.height += float64(len(o.signature.text())) * signatureLineHeight
+		placements = append(placements, pdfPlacement{name: "signature", page: o.signature.page, rect: rect})
+	}
+	return placements, nil
+}
+
+// build lays out the document, converting images to a PDF when any are configured
+func (o pdfOptions) build() (*gofpdf.Fpdf, error) {
+	if len(o.images.filenames) > 0 {
+		return buildImagePDF(o)
+	}
+	return buildPDF(o)
+}
+
+// renderPDFContent renders the PDF in memory and returns its content, digitally signed if configured
+func renderPDFContent(opts pdfOptions) ([]byte, error) {
+	pdf, err := opts.build()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.digitalSignature != nil {
+		return opts.digitalSignature.sign(buf.Bytes(), opts.creationDate)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildPDF lays out header + content
+func buildPDF(opts pdfOptions) (*gofpdf.Fpdf, error) {
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := opts.newDocument(defaultMargin)
+	tr := opts.font.register(pdf)
+	err = opts.letterhead.apply(pdf, opts.font, tr, opts.layout)
+	if err != nil {
+		return nil, err
+	}
 	pdf.AddPage()
-	pdf.SetTitle(header, false)
-	pdf.SetFont("Arial", "B", 16)
-	// Calculate width of title and position
-	wd := pdf.GetStringWidth(header) + 6
-	pdf.SetX((210 - wd) / 2)
-	// Title
-	pdf.CellFormat(wd, 9, header, "", 1, "C", false, 0, "")
+	if opts.addressWindow != nil {
+		err = opts.addressWindow.render(pdf, opts.font, tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pdf.SetTitle(opts.header, true)
+	pdf.SetFont(opts.font.family, opts.font.style("B"), headerFontSize)
+	// Title, centered between the margins
+	pdf.CellFormat(0, headerLineHeight, tr(opts.header), "", 1, "C", false, 0, "")
 	// Line break
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 11)
-	pdf.SetAutoPageBreak(true, 2.00)
+	pdf.Ln(headerSpacing)
+	pdf.SetFont(opts.font.family, opts.font.style(""), opts.layout.fontSize)
 	// Write ze content
-	pdf.Write(8, content)
+	pdf.Write(opts.layout.lineHeight, tr(opts.content))
+	for _, table := range opts.tables {
+		table.render(pdf, opts.font, tr)
+	}
+	for _, code := range opts.barcodes {
+		err = code.render(pdf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.signature != nil {
+		err = opts.signature.render(pdf, opts.font, tr)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return pdf.OutputFileAndClose(outputFilePath)
+	return pdf, nil
 }
 
-// convertImage converts input image path to pdf file
-func convertImage(inputFilePath, outputFilePath string) error {
-	pdf := gofpdf.New(gofpdf.OrientationPortrait, "mm", gofpdf.PageSizeLetter, "")
-	pdf.AddPage()
-	pdf.Image(inputFilePath, 0, 0, 220, 280, false, "", 0, "")
+// buildImagePDF lays out input images, one image per page
+func buildImagePDF(opts pdfOptions) (*gofpdf.Fpdf, error) {
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
 
-	err := pdf.OutputFileAndClose(outputFilePath)
+	// Images stay clear of the band the printer leaves blank unless margins are configured
+	pdf := opts.newDocument(unprintableMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	err = opts.images.render(pdf, opts.layout)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for _, code := range opts.barcodes {
+		err = code.render(pdf)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	return pdf, nil
 }
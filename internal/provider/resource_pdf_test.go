@@ -1,19 +1,26 @@
 package provider
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"golang.org/x/exp/slices"
 )
 
 func TestAccResourcePDF(t *testing.T) {
 	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccResourcePDF,
@@ -28,6 +35,23 @@ func TestAccResourcePDF(t *testing.T) {
 					resource.TestMatchResourceAttr(
 						"mailform_pdf.example", "filename", regexp.MustCompile("./test.pdf"),
 					),
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "page_count", "1",
+					),
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "rendered_page_width", "215.9",
+					),
+					resource.TestCheckResourceAttrSet(
+						"mailform_pdf.example", "sha256",
+					),
+				),
+			},
+			{
+				Config: testAccResourcePDFUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "content", "Updated resume contents",
+					),
 				),
 			},
 		},
@@ -43,6 +67,100 @@ resource "mailform_pdf" "example" {
   }
 `
 
+const testAccResourcePDFUpdated = `
+resource "mailform_pdf" "example" {
+	header   = "My Resume"
+	content  = "Updated resume contents"
+	filename = "./test.pdf"
+  }
+`
+
+func TestAccResourcePDFFont(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourcePDFFontUnsupported,
+				ExpectError: regexp.MustCompile("cannot render characters"),
+			},
+			{
+				Config: testAccResourcePDFFont,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "font_family", "Calligrapher",
+					),
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "fonts.0.bold", "./testdata/calligra.ttf",
+					),
+				),
+			},
+		},
+		CheckDestroy: checkFileDeleted("./test_font.pdf"),
+	})
+}
+
+const testAccResourcePDFFont = `
+resource "mailform_pdf" "example" {
+	header      = "Crème brûlée"
+	content     = "Café"
+	filename    = "./test_font.pdf"
+	font_family = "Calligrapher"
+	font_file   = "./testdata/calligra.ttf"
+	fonts {
+		bold = "./testdata/calligra.ttf"
+	}
+}
+`
+
+const testAccResourcePDFFontUnsupported = `
+resource "mailform_pdf" "example" {
+	header    = "Invoice"
+	content   = "Total: 10 €"
+	filename  = "./test_font.pdf"
+	font_file = "./testdata/calligra.ttf"
+}
+`
+
+func TestAccResourcePDFSourceFile(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourcePDFSourceFileInvalid,
+				ExpectError: regexp.MustCompile("not a PDF"),
+			},
+			{
+				Config: testAccResourcePDFSourceFile,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"mailform_pdf.example", "page_count", "1",
+					),
+					resource.TestCheckResourceAttrSet(
+						"mailform_pdf.example", "sha256",
+					),
+				),
+			},
+		},
+		CheckDestroy: checkFileDeleted("./test_source.pdf"),
+	})
+}
+
+const testAccResourcePDFSourceFile = `
+resource "mailform_pdf" "example" {
+	source_file = "./testdata/source.pdf"
+	filename    = "./test_source.pdf"
+}
+`
+
+const testAccResourcePDFSourceFileInvalid = `
+resource "mailform_pdf" "example" {
+	source_file = "./testdata/calligra.ttf"
+	filename    = "./test_source.pdf"
+}
+`
+
 func checkFileDeleted(shouldNotExistFile string) resource.TestCheckFunc {
 	return func(*terraform.State) error {
 		if _, err := os.Stat(shouldNotExistFile); os.IsNotExist(err) {
@@ -51,3 +169,183 @@ func checkFileDeleted(shouldNotExistFile string) resource.TestCheckFunc {
 		return fmt.Errorf("file %s was not deleted", shouldNotExistFile)
 	}
 }
+
+func TestRenderPDFDeterministic(t *testing.T) {
+	opts := pdfOptions{
+		header:       "Café",
+		content:      "Crème brûlée",
+		font:         pdfFont{family: "calligra", file: testFontFile, bold: testFontFile},
+		layout:       testPDFLayout(defaultPageSize, orientationPortrait),
+		letterhead:   pdfLetterhead{image: writeTestImage(t, 400, 50), footerText: "Footer", pageNumbers: true},
+		tables:       []pdfTable{testPDFTable(60)},
+		barcodes:     []pdfBarcode{{symbology: symbologyQR, payload: "payload", page: 1, rect: pdfRect{x: 170, y: 240, width: 25, height: 25}}},
+		creationDate: defaultCreationDate,
+	}
+
+	render := func() []byte {
+		content, err := renderPDFContent(opts)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return content
+	}
+
+	first := render()
+	time.Sleep(time.Second)
+	if second := render(); !bytes.Equal(first, second) {
+		t.Error("expected identical inputs to render identical PDFs")
+	}
+
+	opts.creationDate = time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if third := render(); bytes.Equal(first, third) {
+		t.Error("expected creation date to change the rendered PDF")
+	}
+	if !bytes.Contains(render(), []byte("D:20230301000000")) {
+		t.Error("expected creation date to be embedded")
+	}
+}
+
+// testUnknownValue is how the SDK represents values that are unknown until apply in raw configuration
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestResourcePDFCustomizeDiff(t *testing.T) {
+	// Without an owner_password, user_password fails validation
+	invalid := map[string]any{
+		"filename":      filepath.Join(t.TempDir(), "output.pdf"),
+		"user_password": "secret",
+	}
+	tests := []struct {
+		name    string
+		unknown string
+		valid   bool
+	}{
+		{name: "known"},
+		{name: "unknown images", unknown: "images", valid: true},
+		{name: "unknown image_filename", unknown: "image_filename", valid: true},
+		{name: "unknown letterhead_image", unknown: "letterhead_image", valid: true},
+		{name: "unknown creation_date", unknown: "creation_date", valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := map[string]any{}
+			for key, value := range invalid {
+				config[key] = value
+			}
+			if test.unknown != "" {
+				config[test.unknown] = testUnknownValue
+			}
+
+			_, err := resourcePDF().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), nil)
+			if test.valid && err != nil {
+				t.Errorf("expected validation to wait for %s, got %s", test.unknown, err)
+			}
+			if !test.valid && err == nil {
+				t.Error("expected a known configuration to be validated")
+			}
+		})
+	}
+
+	keys := pdfOptionKeys()
+	for _, key := range []string{"images", "image_filename", "letterhead_image", "creation_date", "digital_signature"} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("expected %s to be validated once known", key)
+		}
+	}
+	for _, key := range append([]string{"filename", "source_file"}, pdfMetadataKeys...) {
+		if slices.Contains(keys, key) {
+			t.Errorf("expected %s not to be an option of the rendered PDF", key)
+		}
+	}
+}
+
+func TestWritePDFResource(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output.pdf")
+	write := func(config map[string]any) diag.Diagnostics {
+		config["filename"] = output
+		return writePDFResource(context.Background(), schema.TestResourceDataRaw(t, resourcePDF().Schema, config))
+	}
+
+	if diags := write(map[string]any{"header": "Header", "content": "Content"}); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	original, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A failed render leaves the existing PDF untouched
+	margins := []any{map[string]any{"top": 200.0, "right": 200.0, "bottom": 200.0, "left": 200.0}}
+	if diags := write(map[string]any{"header": "Header", "content": "Content", "margins": margins}); !diags.HasError() {
+		t.Fatal("expected oversized margins to fail rendering")
+	}
+	if content, _ := os.ReadFile(output); !bytes.Equal(original, content) {
+		t.Error("expected failed render to leave the PDF untouched")
+	}
+
+	if diags := write(map[string]any{"header": "Header", "content": "Updated content"}); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if content, _ := os.ReadFile(output); bytes.Equal(original, content) {
+		t.Error("expected PDF to be replaced")
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if info.Mode().Perm() != pdfFileMode {
+		t.Errorf("expected mode %o, got %o", pdfFileMode, info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temporary files to be removed, found %d files", len(entries))
+	}
+}
+
+func TestResourcePDFReadSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.pdf")
+	content, err := os.ReadFile(filepath.Join("testdata", "source.pdf"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.WriteFile(source, content, 0o600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePDF().Schema, map[string]any{"source_file": source, "filename": filepath.Join(dir, "output.pdf")})
+	if diags := writePDFResource(context.Background(), d); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	id := d.Id()
+
+	if diags := resourcePDFRead(context.Background(), d, nil); len(diags) != 0 || d.Id() != id {
+		t.Fatalf("expected an unchanged source to keep the PDF, got %v", diags)
+	}
+
+	// A missing source is reported without removing the PDF from state
+	if err := os.Remove(source); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diags := resourcePDFRead(context.Background(), d, nil)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "PDF source file not found" {
+		t.Errorf("expected a warning for the missing source, got %v", diags)
+	}
+	if d.Id() != id {
+		t.Error("expected a missing source to keep the PDF in state")
+	}
+
+	// A changed source is drift
+	if err := os.WriteFile(source, append(content, '\n'), 0o600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diags := resourcePDFRead(context.Background(), d, nil); len(diags) != 0 || d.Id() != "" {
+		t.Errorf("expected a changed source to replace the PDF, got %v", diags)
+	}
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/circa10a/go-mailform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTraceExporter records the spans of the provider in memory for the duration of a test
+func testTraceExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return exporter
+}
+
+// spanAttribute returns the value of an attribute of a span
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingOrder(t *testing.T) {
+	exporter := testTraceExporter(t)
+
+	interval := orderStatusPollInterval
+	orderStatusPollInterval = 10 * time.Millisecond
+	testAccServer.AdvanceOnRead = true
+	defer func() {
+		orderStatusPollInterval = interval
+		testAccServer.AdvanceOnRead = false
+	}()
+
+	ctx := context.Background()
+	meta, err := newProviderConfig(ctx, testAccAPIToken, testAccServer.URL, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceMailformOrder().Schema, map[string]any{
+		"pdf_url":              "https://example.com/letter.pdf",
+		"wait_until_fulfilled": true,
+		"service":              "USPS_FIRST_CLASS",
+		"to_name":              "A name",
+		"to_address_1":         "Address 1",
+		"to_city":              "Seattle",
+		"to_state":             "WA",
+		"to_postcode":          "00000",
+		"to_country":           "US",
+		"from_name":            "My name",
+		"from_address_1":       "My Address 1",
+		"from_city":            "Dallas",
+		"from_state":           "TX",
+		"from_postcode":        "00000",
+		"from_country":         "US",
+	})
+	if diags := resourceMailformOrderCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	spans := exporter.GetSpans()
+	names := map[string]int{}
+	var create tracetest.SpanStub
+	for _, span := range spans {
+		names[span.Name]++
+		if span.Name == spanOrderCreate {
+			create = span
+		}
+	}
+	for _, name := range []string{spanConfigure, spanOrderCreate, spanPDFUpload, spanOrderRead} {
+		if names[name] != 1 {
+			t.Errorf("expected 1 %s span, got %d", name, names[name])
+		}
+	}
+	// Awaiting fulfillment, then fulfilled
+	if names[spanOrderPoll] < 2 {
+		t.Errorf("expected a span for each poll, got %d", names[spanOrderPoll])
+	}
+
+	if id := spanAttribute(create, attrOrderID).AsString(); id != d.Id() {
+		t.Errorf("expected create span for order %s, got %q", d.Id(), id)
+	}
+	if service := spanAttribute(create, attrService).AsString(); service != "USPS_FIRST_CLASS" {
+		t.Errorf("expected create span for USPS_FIRST_CLASS, got %q", service)
+	}
+	for _, span := range spans {
+		if span.Name == spanOrderCreate || span.Name == spanConfigure {
+			continue
+		}
+		if span.Parent.SpanID() != create.SpanContext.SpanID() {
+			t.Errorf("expected %s to be a child of %s", span.Name, spanOrderCreate)
+		}
+		if span.Name == spanOrderRead && spanAttribute(span, attrOrderState).AsString() != mailform.StatusFulfilled {
+			t.Errorf("expected read span of a fulfilled order, got %v", span.Attributes)
+		}
+	}
+}
+
+func TestTracingOrderError(t *testing.T) {
+	exporter := testTraceExporter(t)
+
+	meta, err := newProviderConfig(context.Background(), testAccAPIToken, testAccServer.URL, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, dataSourceOrder().Schema, map[string]any{"id": "ord_unknown"})
+	testAccServer.FailNext(http.StatusInternalServerError, "erroroccurred", "unknown_error")
+	if diags := orderRead(context.Background(), d, meta); !diags.HasError() {
+		t.Fatal("expected error")
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name != spanOrderRead {
+			continue
+		}
+		if span.Status.Code != codes.Error || span.Status.Description != "unknown_error" {
+			t.Errorf("expected the read span to record the error, got %+v", span.Status)
+		}
+		return
+	}
+	t.Errorf("expected a %s span", spanOrderRead)
+}
+
+func TestTracingPDFRender(t *testing.T) {
+	exporter := testTraceExporter(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourcePDF().Schema, map[string]any{"header": "Hello", "content": "World"})
+	if diags := dataSourcePDFRead(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != spanPDFRender {
+		t.Fatalf("expected a %s span, got %v", spanPDFRender, spans)
+	}
+	if pages := spanAttribute(spans[0], attrPDFPages).AsInt64(); pages != 1 {
+		t.Errorf("expected 1 page, got %d", pages)
+	}
+	if source := spanAttribute(spans[0], attrPDFSource).AsString(); source != "render" {
+		t.Errorf("expected a rendered PDF, got %q", source)
+	}
+}
+
+func TestStartTracing(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	stop, err := StartTracing(context.Background(), "dev")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := stop(context.Background()); err != nil {
+		t.Errorf("expected tracing to be disabled without an endpoint, got %s", err)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	if _, err := StartTracing(context.Background(), "dev"); err == nil {
+		t.Error("expected unsupported protocol to be rejected")
+	}
+}
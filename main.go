@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"github.com/circa10a/terraform-provider-mailform/internal/provider"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 )
 
 // Run "go generate" to format example terraform files and generate the docs for the registry/website
@@ -32,13 +34,28 @@ func main() {
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{
-		Debug: debugMode,
+	ctx := context.Background()
+	stopTracing, err := provider.StartTracing(ctx, version)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		ProviderAddr: "registry.terraform.io/circa10a/terraform-provider-mailform",
+	muxServer, err := provider.NewMuxServer(ctx, version)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		ProviderFunc: provider.New(version),
+	var serveOpts []tf6server.ServeOpt
+	if debugMode {
+		serveOpts = append(serveOpts, tf6server.WithManagedDebug())
 	}
 
-	plugin.Serve(opts)
+	err = tf6server.Serve("registry.terraform.io/circa10a/terraform-provider-mailform", muxServer, serveOpts...)
+	// Spans are flushed once Terraform stops the provider
+	if stopErr := stopTracing(ctx); stopErr != nil {
+		log.Println(stopErr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }